@@ -8,16 +8,28 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 
+	"github.com/quickfixgo/quickfix/datadictionary"
+
 	"sylr.dev/fix/config"
 	"sylr.dev/fix/pkg/acceptor"
 	"sylr.dev/fix/pkg/acceptor/application"
+	"sylr.dev/fix/pkg/acceptor/gtc"
+	"sylr.dev/fix/pkg/acceptor/refdata"
+	"sylr.dev/fix/pkg/acceptor/risk"
+	"sylr.dev/fix/pkg/acceptor/rules"
+	"sylr.dev/fix/pkg/acceptor/sink"
+	"sylr.dev/fix/pkg/admin"
 	"sylr.dev/fix/pkg/utils"
 )
 
 var (
-	optionNatsEmbeded      bool
-	optionNatsURL          string
-	optionNatsOrderSubject string
+	optionNatsEmbeded          bool
+	optionNatsURL              string
+	optionNatsOrderSubject     string
+	optionNatsBridge           bool
+	optionNatsCancelSubject    string
+	optionNatsReplaceSubject   string
+	optionNatsExecutionSubject string
 )
 
 var AcceptorCmd = &cobra.Command{
@@ -31,7 +43,11 @@ var AcceptorCmd = &cobra.Command{
 func init() {
 	AcceptorCmd.Flags().StringVar(&optionNatsURL, "nats-url", "nats://127.0.0.1:4222", "NATS URL used to forward FIX messages")
 	AcceptorCmd.Flags().StringVar(&optionNatsOrderSubject, "nats-order-subject", "orders.{{.Symbol}}.{{.Side}}.{{.Type}}", "NATS order subject")
+	AcceptorCmd.Flags().StringVar(&optionNatsCancelSubject, "nats-cancel-subject", "cancels.{{.Symbol}}.{{.Side}}", "NATS order cancel subject (bridge mode)")
+	AcceptorCmd.Flags().StringVar(&optionNatsReplaceSubject, "nats-replace-subject", "replaces.{{.Symbol}}.{{.Side}}.{{.Type}}", "NATS order cancel/replace subject (bridge mode)")
+	AcceptorCmd.Flags().StringVar(&optionNatsExecutionSubject, "nats-execution-subject", "executions.>", "NATS subject of the messages to relay to FIX sessions (bridge mode)")
 	utils.AddBothBoolFlags(AcceptorCmd.Flags(), &optionNatsEmbeded, "nats-embeded", "", true, "Launch embeded NATS server")
+	utils.AddBothBoolFlags(AcceptorCmd.Flags(), &optionNatsBridge, "nats-bridge", "", false, "Let a downstream OMS handle orders through NATS")
 
 	acceptor.AddPersistentFlags(AcceptorCmd)
 	acceptor.AddPersistentFlagCompletions(AcceptorCmd)
@@ -51,20 +67,101 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	acceptorConfig, err := context.GetAcceptor()
+	if err != nil {
+		return err
+	}
+
 	settings, err := context.ToQuickFixAcceptorSettings()
 	if err != nil {
 		return err
 	}
 
-	transportDict, appDict, err := sessions[0].GetFIXDictionaries()
+	dynamicSettings, err := context.ToQuickFixDynamicSessionSettings()
 	if err != nil {
 		return err
 	}
 
+	if acceptorConfig.DynamicSessions != nil {
+		template, err := config.GetSession(acceptorConfig.DynamicSessions.Session)
+		if err != nil {
+			return err
+		}
+		sessions = append(sessions, template)
+	}
+
+	// Sessions can speak different FIX versions, the dictionaries of the
+	// first session are used when the version is unknown.
+	var transportDict, appDict *datadictionary.DataDictionary
+	dictionaries := make(map[string]application.Dictionaries)
+	for i, session := range sessions {
+		td, ad, err := session.GetFIXDictionaries()
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			transportDict, appDict = td, ad
+		}
+		if _, ok := dictionaries[session.BeginString]; !ok {
+			dictionaries[session.BeginString] = application.Dictionaries{Transport: td, App: ad}
+		}
+	}
+
 	acceptorOptions := application.AcceptorOptions{
 		NATSEmbeded:      optionNatsEmbeded,
 		NATSURL:          optionNatsURL,
 		NATSOrderSubject: optionNatsOrderSubject,
+		FillMode:         application.FillMode(acceptorConfig.FillMode),
+		StopTrigger:      application.StopTrigger(acceptorConfig.StopTrigger),
+
+		NATSBridge:           optionNatsBridge,
+		NATSCancelSubject:    optionNatsCancelSubject,
+		NATSReplaceSubject:   optionNatsReplaceSubject,
+		NATSExecutionSubject: optionNatsExecutionSubject,
+	}
+
+	referenceDataFile := os.ExpandEnv(acceptorConfig.ReferenceDataFile)
+	if len(referenceDataFile) > 0 {
+		acceptorOptions.ReferenceData, err = refdata.Load(referenceDataFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	rulesFile := os.ExpandEnv(acceptorConfig.RulesFile)
+	if len(rulesFile) > 0 {
+		acceptorOptions.Rules, err = rules.Load(rulesFile, appDict)
+		if err != nil {
+			return err
+		}
+	}
+
+	riskLimitsFile := os.ExpandEnv(acceptorConfig.RiskLimitsFile)
+	if len(riskLimitsFile) > 0 {
+		acceptorOptions.RiskLimits, err = risk.Load(riskLimitsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	// GTC orders are kept in the SQL store database
+	if driver := acceptorConfig.GetSQLStoreDriver(); len(driver) > 0 {
+		acceptorOptions.GTCStore, err = gtc.Open(driver, os.ExpandEnv(acceptorConfig.GetSQLStoreDataSourceName()))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, conf := range acceptorConfig.Sinks {
+		conf.URL = os.ExpandEnv(conf.URL)
+		if conf.Type == "file" || conf.Type == "unix" {
+			conf.Template = os.ExpandEnv(conf.Template)
+		}
+		s, err := sink.New(conf, logger)
+		if err != nil {
+			return err
+		}
+		acceptorOptions.Sinks = append(acceptorOptions.Sinks, s)
 	}
 
 	app, err := application.NewAcceptor(&acceptorOptions)
@@ -74,14 +171,20 @@ func Execute(cmd *cobra.Command, args []string) error {
 
 	app.TransportDataDictionary = transportDict
 	app.AppDataDictionary = appDict
+	app.Dictionaries = dictionaries
 	app.Logger = logger
+	app.Settings = settings
+	app.DynamicSettings = dynamicSettings
+	app.Start()
+
+	admin.Handle("/admin/", app.AdminHandler())
 
 	var quickfixLogger *zerolog.Logger
 	if options.QuickFixLogging {
 		quickfixLogger = logger
 	}
 
-	acceptor, err := acceptor.NewAcceptor(app, settings, quickfixLogger)
+	acceptor, err := acceptor.NewAcceptor(app, settings, dynamicSettings, quickfixLogger)
 	if err != nil {
 		return err
 	}
@@ -93,14 +196,49 @@ func Execute(cmd *cobra.Command, args []string) error {
 
 	defer func() {
 		acceptor.Stop()
+		app.Close()
 	}()
 
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
 
-	<-interrupt
-	acceptor.Stop()
-	os.Exit(0)
-
-	return nil
+	// Reload reference data, rules and risk limits on SIGHUP
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	for {
+		select {
+		case <-hangup:
+			if len(referenceDataFile) > 0 {
+				if data, err := refdata.Load(referenceDataFile); err != nil {
+					logger.Error().Err(err).Msgf("Unable to reload reference data from %s", referenceDataFile)
+				} else {
+					logger.Info().Msgf("Reference data reloaded from %s", referenceDataFile)
+					app.SetReferenceData(data)
+				}
+			}
+
+			if len(rulesFile) > 0 {
+				if r, err := rules.Load(rulesFile, appDict); err != nil {
+					logger.Error().Err(err).Msgf("Unable to reload rules from %s", rulesFile)
+				} else {
+					logger.Info().Msgf("Rules reloaded from %s", rulesFile)
+					app.SetRules(r)
+				}
+			}
+
+			if len(riskLimitsFile) > 0 {
+				if limits, err := risk.Load(riskLimitsFile); err != nil {
+					logger.Error().Err(err).Msgf("Unable to reload risk limits from %s", riskLimitsFile)
+				} else {
+					logger.Info().Msgf("Risk limits reloaded from %s", riskLimitsFile)
+					app.SetRiskLimits(limits)
+				}
+			}
+		case <-interrupt:
+			acceptor.Stop()
+			app.Close()
+			os.Exit(0)
+		}
+	}
 }
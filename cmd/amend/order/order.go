@@ -22,6 +22,7 @@ import (
 	"sylr.dev/fix/config"
 	"sylr.dev/fix/pkg/cli/complete"
 	"sylr.dev/fix/pkg/cli/options"
+	"sylr.dev/fix/pkg/daemon"
 	"sylr.dev/fix/pkg/dict"
 	"sylr.dev/fix/pkg/errors"
 	"sylr.dev/fix/pkg/initiator"
@@ -123,12 +124,11 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sessions, err := context.GetSessions()
+	session, err := context.GetSessionFor(enum.MsgType_ORDER_CANCEL_REPLACE_REQUEST, options.Session)
 	if err != nil {
 		return err
 	}
 
-	session := sessions[0]
 	initiatorConfig, err := context.GetInitiator()
 	if err != nil {
 		return err
@@ -139,7 +139,7 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	settings, err := context.ToQuickFixInitiatorSettings()
+	settings, err := context.ToQuickFixInitiatorSettings(session.Name)
 	if err != nil {
 		return err
 	}
@@ -165,39 +165,56 @@ func Execute(cmd *cobra.Command, args []string) error {
 		timeout = 5 * time.Second
 	}
 
-	init, err := initiator.Initiate(app, settings, quickfixLogger)
-	if err != nil {
-		return err
-	}
+	var send func(quickfix.Messagable) error
+	var messages <-chan *quickfix.Message
 
-	// Start session
-	if err = init.Start(); err != nil {
-		return err
-	}
+	// Send through fix daemon when it is running, it owns the sessions
+	if client, err := daemon.Dial(logger, options.DaemonSocket, transportDict, appDict); err == nil {
+		logger.Debug().Msgf("Sending through daemon: %s", options.DaemonSocket)
+		defer client.Close()
+
+		send = func(m quickfix.Messagable) error {
+			return client.Send(context.Name, *session, m)
+		}
+		messages = client.Messages()
+	} else {
+		init, err := initiator.Initiate(app, settings, quickfixLogger)
+		if err != nil {
+			return err
+		}
 
-	defer func() {
-		app.Stop()
-		init.Stop()
-	}()
-
-	// Wait for session connection
-	select {
-	case <-time.After(timeout):
-		return errors.ConnectionTimeout
-	case _, ok := <-app.Connected:
-		if !ok {
-			return errors.FixLogout
+		// Start session
+		if err = init.Start(); err != nil {
+			return err
 		}
+
+		defer func() {
+			app.Stop()
+			init.Stop()
+		}()
+
+		// Wait for session connection
+		select {
+		case <-time.After(timeout):
+			return errors.ConnectionTimeout
+		case _, ok := <-app.Connected:
+			if !ok {
+				return errors.FixLogout
+			}
+		}
+
+		send = quickfix.Send
+		messages = app.FromAppMessages
 	}
 
 	// Prepare order
-	order, err := buildMessage(*session)
+	order, err := BuildMessage(*session)
 	if err != nil {
 		return err
 	}
 
 	// Send the order
-	err = quickfix.Send(order)
+	err = send(order)
 	if err != nil {
 		return err
 	}
@@ -224,7 +241,7 @@ LOOP:
 			logger.Warn().Msgf("Timeout while expecting execution reports (%d/%d)", execReports, optionExecReports)
 			break LOOP
 
-		case msg, ok := <-app.FromAppMessages:
+		case msg, ok := <-messages:
 			if !ok {
 				break LOOP
 			}
@@ -258,7 +275,9 @@ LOOP:
 	return nil
 }
 
-func buildMessage(session config.Session) (quickfix.Messagable, error) {
+// BuildMessage builds the message of the command for the session from its
+// options, it is also used by fix shell.
+func BuildMessage(session config.Session) (quickfix.Messagable, error) {
 	eSide, err := dict.OrderSideStringToEnum(optionOrderSide)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,494 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	neworder "sylr.dev/fix/cmd/new/order"
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/cli/complete"
+	"sylr.dev/fix/pkg/daemon"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/initiator"
+	"sylr.dev/fix/pkg/initiator/application"
+	"sylr.dev/fix/pkg/utils"
+)
+
+var (
+	optionOrderSymbol          string
+	optionOrderType            string
+	optionOrderExpiry          string
+	optionBuyRatio             float64
+	optionPrice                float64
+	optionPriceDeviation       float64
+	optionPriceDistribution    string
+	optionQuantity             float64
+	optionQuantityDeviation    float64
+	optionQuantityDistribution string
+	optionRate                 float64
+	optionDuration             time.Duration
+	optionDrainTimeout         time.Duration
+	optionLinger               time.Duration
+	optionSeed                 int64
+)
+
+var distributions = []string{"fixed", "uniform", "normal"}
+
+var BenchCmd = &cobra.Command{
+	Use:               "bench",
+	Short:             "Benchmark order entry",
+	Long:              "Send orders at a given rate after initiating a session with a FIX acceptor and measure their latency.",
+	Args:              cobra.ExactArgs(0),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	PersistentPreRunE: utils.MakePersistentPreRunE(Validate),
+	RunE:              Execute,
+}
+
+func init() {
+	initiator.AddPersistentFlags(BenchCmd)
+	initiator.AddPersistentFlagCompletions(BenchCmd)
+
+	BenchCmd.Flags().StringVar(&optionOrderSymbol, "symbol", "", "Order symbol")
+	BenchCmd.Flags().StringVar(&optionOrderType, "type", "limit", "Order type (market, limit)")
+	BenchCmd.Flags().StringVar(&optionOrderExpiry, "expiry", "day", "Order expiry (day, immediate_or_cancel ... etc)")
+	BenchCmd.Flags().Float64Var(&optionBuyRatio, "buy-ratio", 0.5, "Ratio of buy orders, the others are sell orders")
+	BenchCmd.Flags().Float64Var(&optionPrice, "price", 0.0, "Mean order price")
+	BenchCmd.Flags().Float64Var(&optionPriceDeviation, "price-deviation", 0.0, "Order price deviation (half range for uniform, standard deviation for normal)")
+	BenchCmd.Flags().StringVar(&optionPriceDistribution, "price-distribution", "fixed", "Order price distribution (fixed, uniform, normal)")
+	BenchCmd.Flags().Float64Var(&optionQuantity, "quantity", 1, "Mean order quantity")
+	BenchCmd.Flags().Float64Var(&optionQuantityDeviation, "quantity-deviation", 0.0, "Order quantity deviation (half range for uniform, standard deviation for normal)")
+	BenchCmd.Flags().StringVar(&optionQuantityDistribution, "quantity-distribution", "fixed", "Order quantity distribution (fixed, uniform, normal)")
+	BenchCmd.Flags().Float64Var(&optionRate, "rate", 100, "Orders sent per second")
+	BenchCmd.Flags().DurationVar(&optionDuration, "duration", 10*time.Second, "Duration orders are sent for")
+	BenchCmd.Flags().DurationVar(&optionDrainTimeout, "drain-timeout", 5*time.Second, "Time given to the last orders to be acknowledged")
+	BenchCmd.Flags().DurationVar(&optionLinger, "linger", 0, "Time /metrics is still served after the report")
+	BenchCmd.Flags().Int64Var(&optionSeed, "seed", 0, "Seed of the order distributions (0 for a random one)")
+
+	BenchCmd.MarkFlagRequired("symbol")
+
+	BenchCmd.RegisterFlagCompletionFunc("symbol", cobra.NoFileCompletions)
+	BenchCmd.RegisterFlagCompletionFunc("type", cobra.FixedCompletions([]string{"market", "limit"}, cobra.ShellCompDirectiveNoFileComp))
+	BenchCmd.RegisterFlagCompletionFunc("expiry", complete.OrderTimeInForce)
+	BenchCmd.RegisterFlagCompletionFunc("price-distribution", cobra.FixedCompletions(distributions, cobra.ShellCompDirectiveNoFileComp))
+	BenchCmd.RegisterFlagCompletionFunc("quantity-distribution", cobra.FixedCompletions(distributions, cobra.ShellCompDirectiveNoFileComp))
+
+	prometheus.MustRegister(metricBenchAckLatency)
+	prometheus.MustRegister(metricBenchFillLatency)
+}
+
+func Validate(cmd *cobra.Command, args []string) error {
+	switch strings.ToLower(optionOrderType) {
+	case "market", "limit":
+	default:
+		return errors.OptionOrderTypeUnknown
+	}
+
+	if utils.Search(distributions, strings.ToLower(optionPriceDistribution)) < 0 {
+		return fmt.Errorf("%w: %s", errors.OptionDistributionUnknown, optionPriceDistribution)
+	}
+
+	if utils.Search(distributions, strings.ToLower(optionQuantityDistribution)) < 0 {
+		return fmt.Errorf("%w: %s", errors.OptionDistributionUnknown, optionQuantityDistribution)
+	}
+
+	if optionBuyRatio < 0 || optionBuyRatio > 1 {
+		return fmt.Errorf("%w: --buy-ratio must be between 0 and 1", errors.Options)
+	}
+
+	if optionRate <= 0 || optionDuration <= 0 {
+		return fmt.Errorf("%w: --rate and --duration must be positive", errors.Options)
+	}
+
+	if optionLinger < 0 {
+		return fmt.Errorf("%w: --linger must not be negative", errors.Options)
+	}
+
+	if strings.ToLower(optionOrderType) == "market" && optionPrice > 0 {
+		return errors.OptionsInvalidMarketPrice
+	} else if strings.ToLower(optionOrderType) != "market" && optionPrice <= 0 {
+		return errors.OptionsNoPriceGiven
+	}
+
+	return initiator.ValidateOptions(cmd, args)
+}
+
+// Metrics
+var (
+	latencyBuckets = prometheus.ExponentialBuckets(0.0001, 2, 16)
+
+	metricBenchAckLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "fix",
+			Subsystem: "bench",
+			Name:      "order_ack_latency_seconds",
+			Help:      "Latency between sending an order and receiving its first execution report",
+			Buckets:   latencyBuckets,
+		},
+		[]string{"context", "session"},
+	)
+	metricBenchFillLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "fix",
+			Subsystem: "bench",
+			Name:      "order_fill_latency_seconds",
+			Help:      "Latency between sending an order and receiving its first fill",
+			Buckets:   latencyBuckets,
+		},
+		[]string{"context", "session"},
+	)
+)
+
+// distribution draws values around a mean.
+type distribution struct {
+	kind      string
+	mean      float64
+	deviation float64
+}
+
+func (d distribution) draw(r *rand.Rand) float64 {
+	switch strings.ToLower(d.kind) {
+	case "uniform":
+		return d.mean + (2*r.Float64()-1)*d.deviation
+	case "normal":
+		return d.mean + r.NormFloat64()*d.deviation
+	default:
+		return d.mean
+	}
+}
+
+// benchOrder is an order sent during the benchmark.
+type benchOrder struct {
+	sentAt time.Time
+	acked  bool
+	filled bool
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	options := config.GetOptions()
+	logger := config.GetLogger()
+
+	context, err := config.GetCurrentContext()
+	if err != nil {
+		return err
+	}
+
+	session, err := context.GetSessionFor(enum.MsgType_ORDER_SINGLE, options.Session)
+	if err != nil {
+		return err
+	}
+
+	initiatorConfig, err := context.GetInitiator()
+	if err != nil {
+		return err
+	}
+
+	transportDict, appDict, err := session.GetFIXDictionaries()
+	if err != nil {
+		return err
+	}
+
+	settings, err := context.ToQuickFixInitiatorSettings(session.Name)
+	if err != nil {
+		return err
+	}
+
+	app := application.NewNewOrder()
+	app.Logger = logger
+	app.Settings = settings
+	app.TransportDataDictionary = transportDict
+	app.AppDataDictionary = appDict
+
+	var quickfixLogger *zerolog.Logger
+	if options.QuickFixLogging {
+		quickfixLogger = logger
+	}
+
+	// Choose right timeout cli option > config > default value (5s)
+	var timeout time.Duration
+	if options.Timeout != time.Duration(0) {
+		timeout = options.Timeout
+	} else if initiatorConfig.SocketTimeout != time.Duration(0) {
+		timeout = initiatorConfig.SocketTimeout
+	} else {
+		timeout = 5 * time.Second
+	}
+
+	var send func(quickfix.Messagable) error
+	var messages <-chan *quickfix.Message
+
+	// Send through fix daemon when it is running, it owns the sessions
+	if client, err := daemon.Dial(logger, options.DaemonSocket, transportDict, appDict); err == nil {
+		logger.Debug().Msgf("Sending through daemon: %s", options.DaemonSocket)
+		defer client.Close()
+
+		send = func(m quickfix.Messagable) error {
+			return client.Send(context.Name, *session, m)
+		}
+		messages = client.Messages()
+	} else {
+		init, err := initiator.Initiate(app, settings, quickfixLogger)
+		if err != nil {
+			return err
+		}
+
+		// Start session
+		if err = init.Start(); err != nil {
+			return err
+		}
+
+		defer func() {
+			app.Stop()
+			init.Stop()
+		}()
+
+		// Wait for session connection
+		select {
+		case <-time.After(timeout):
+			return errors.ConnectionTimeout
+		case _, ok := <-app.Connected:
+			if !ok {
+				return errors.FixLogout
+			}
+		}
+
+		send = quickfix.Send
+		messages = app.FromAppMessages
+	}
+
+	seed := optionSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	random := rand.New(rand.NewSource(seed))
+	logger.Debug().Msgf("Order distributions seed: %d", seed)
+
+	prices := distribution{kind: optionPriceDistribution, mean: optionPrice, deviation: optionPriceDeviation}
+	quantities := distribution{kind: optionQuantityDistribution, mean: optionQuantity, deviation: optionQuantityDeviation}
+
+	ack := newLatencies("order-to-ack", metricBenchAckLatency.WithLabelValues(context.Name, session.Name))
+	fill := newLatencies("order-to-fill", metricBenchFillLatency.WithLabelValues(context.Name, session.Name))
+
+	orders := make(map[string]*benchOrder)
+	sent, pending, rejected := 0, 0, 0
+
+	// Session level rejects reference the sequence number of the order
+	bySeqNum := make(map[int]string)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	// Orders due since the start are sent on each tick so that rates higher
+	// than the ticker resolution are sustained
+	tick := time.Duration(float64(time.Second) / optionRate)
+	if tick < time.Millisecond {
+		tick = time.Millisecond
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	start := time.Now()
+	ticks := ticker.C
+	end := time.After(optionDuration)
+	var drain <-chan time.Time
+	var elapsed time.Duration
+
+LOOP:
+	for {
+		select {
+		case signal := <-interrupt:
+			logger.Debug().Msgf("Received signal: %s", signal)
+			break LOOP
+
+		case <-end:
+			elapsed = time.Since(start)
+			ticks = nil
+			drain = time.After(optionDrainTimeout)
+
+		case <-drain:
+			logger.Warn().Msgf("Timeout while expecting execution reports of %d orders", pending)
+			break LOOP
+
+		case now := <-ticks:
+			due := int(optionRate*now.Sub(start).Seconds()) - sent
+			for i := 0; i < due; i++ {
+				message, err := buildMessage(*session, random, prices, quantities)
+				if err != nil {
+					return err
+				}
+
+				clOrdID, err := message.Body.GetString(tag.ClOrdID)
+				if err != nil {
+					return err
+				}
+
+				orders[clOrdID] = &benchOrder{sentAt: time.Now()}
+				if err = send(message); err != nil {
+					return err
+				}
+
+				if seqNum, err := message.Header.GetInt(tag.MsgSeqNum); err == nil {
+					bySeqNum[seqNum] = clOrdID
+				}
+
+				sent = sent + 1
+				pending = pending + 1
+			}
+
+		case msg, ok := <-messages:
+			if !ok {
+				break LOOP
+			}
+
+			now := time.Now()
+
+			msgType, err := msg.Header.GetString(tag.MsgType)
+			if err != nil {
+				return err
+			}
+
+			var clOrdID string
+			switch enum.MsgType(msgType) {
+			case enum.MsgType_EXECUTION_REPORT:
+				clOrdID, _ = msg.Body.GetString(tag.ClOrdID)
+			case enum.MsgType_BUSINESS_MESSAGE_REJECT:
+				clOrdID, _ = msg.Body.GetString(tag.BusinessRejectRefID)
+			case enum.MsgType_REJECT:
+				refSeqNum, _ := msg.Body.GetInt(tag.RefSeqNum)
+				if clOrdID, ok = bySeqNum[refSeqNum]; !ok {
+					rejected = rejected + 1
+					continue LOOP
+				}
+			default:
+				continue LOOP
+			}
+
+			o, ok := orders[clOrdID]
+			if !ok {
+				continue LOOP
+			}
+
+			if !o.acked {
+				o.acked = true
+				pending = pending - 1
+				ack.observe(now.Sub(o.sentAt))
+			}
+
+			ordStatus, _ := msg.Body.GetString(tag.OrdStatus)
+
+			if enum.MsgType(msgType) != enum.MsgType_EXECUTION_REPORT || enum.OrdStatus(ordStatus) == enum.OrdStatus_REJECTED {
+				rejected = rejected + 1
+			} else if isFill(msg) && !o.filled {
+				o.filled = true
+				fill.observe(now.Sub(o.sentAt))
+			}
+		}
+
+		if ticks == nil && pending == 0 {
+			break LOOP
+		}
+	}
+
+	if elapsed == 0 {
+		elapsed = time.Since(start)
+	}
+
+	fmt.Printf("Sent %d orders in %s (%.1f orders/s), %d acknowledged, %d filled, %d rejected\n\n",
+		sent, elapsed.Round(time.Millisecond), float64(sent)/elapsed.Seconds(), ack.count(), fill.count(), rejected)
+
+	writeLatencyTable(os.Stdout, ack, fill)
+	fmt.Println()
+	writeHistogram(os.Stdout, ack)
+	fmt.Println()
+	writeHistogram(os.Stdout, fill)
+
+	// Leave the histograms on /metrics some time to be scraped
+	if optionLinger > 0 {
+		select {
+		case <-time.After(optionLinger):
+		case <-interrupt:
+		}
+	}
+
+	return nil
+}
+
+// buildMessage builds an order with fix new order, its flags are set from the
+// distributions as if they had been given on the command line.
+func buildMessage(session config.Session, random *rand.Rand, prices, quantities distribution) (*quickfix.Message, error) {
+	side := "sell"
+	if random.Float64() < optionBuyRatio {
+		side = "buy"
+	}
+
+	quantity := int64(math.Max(1, math.Round(quantities.draw(random))))
+
+	price := 0.0
+	if strings.ToLower(optionOrderType) != "market" {
+		price = math.Max(0.01, math.Round(prices.draw(random)*100)/100)
+	}
+
+	values := map[string]string{
+		"id":       "",
+		"side":     side,
+		"type":     optionOrderType,
+		"symbol":   optionOrderSymbol,
+		"expiry":   optionOrderExpiry,
+		"quantity": strconv.FormatInt(quantity, 10),
+		"price":    strconv.FormatFloat(price, 'f', -1, 64),
+	}
+
+	flags := neworder.NewOrderCmd.Flags()
+	for name, value := range values {
+		if err := flags.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := neworder.Validate(neworder.NewOrderCmd, nil); err != nil {
+		return nil, err
+	}
+
+	message, err := neworder.BuildMessage(session)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.ToMessage(), nil
+}
+
+// isFill returns true if the execution report reports a fill, with a LastQty or
+// with the TRADE ExecType or the PARTIAL_FILL and FILL ones used up to FIX.4.2.
+func isFill(msg *quickfix.Message) bool {
+	lastQty := field.LastQtyField{}
+	if err := msg.Body.GetField(tag.LastQty, &lastQty); err == nil && lastQty.Value().IsPositive() {
+		return true
+	}
+
+	execType := field.ExecTypeField{}
+	if err := msg.Body.GetField(tag.ExecType, &execType); err != nil {
+		return false
+	}
+
+	switch execType.Value() {
+	case enum.ExecType_TRADE, enum.ExecType_PARTIAL_FILL, enum.ExecType_FILL:
+		return true
+	default:
+		return false
+	}
+}
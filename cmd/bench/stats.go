@@ -0,0 +1,142 @@
+package bench
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// latencies keeps the latencies measured for the report printed at the end of
+// the benchmark and observes them in a Prometheus histogram as they come.
+type latencies struct {
+	name      string
+	values    []time.Duration
+	histogram prometheus.Observer
+	sorted    bool
+}
+
+func newLatencies(name string, histogram prometheus.Observer) *latencies {
+	return &latencies{
+		name:      name,
+		histogram: histogram,
+	}
+}
+
+func (l *latencies) observe(d time.Duration) {
+	l.values = append(l.values, d)
+	l.sorted = false
+	l.histogram.Observe(d.Seconds())
+}
+
+func (l *latencies) count() int {
+	return len(l.values)
+}
+
+// percentile returns the latency below which the given percentage of the
+// latencies fall.
+func (l *latencies) percentile(p float64) time.Duration {
+	if len(l.values) == 0 {
+		return 0
+	}
+
+	if !l.sorted {
+		sort.Slice(l.values, func(i, j int) bool { return l.values[i] < l.values[j] })
+		l.sorted = true
+	}
+
+	k := int(float64(len(l.values))*p/100+0.5) - 1
+	if k < 0 {
+		k = 0
+	} else if k >= len(l.values) {
+		k = len(l.values) - 1
+	}
+
+	return l.values[k]
+}
+
+func formatLatency(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	default:
+		return d.String()
+	}
+}
+
+func writeLatencyTable(w io.Writer, all ...*latencies) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"LATENCY", "COUNT", "MIN", "P50", "P99", "P999", "MAX"})
+	table.SetBorders(tablewriter.Border{false, false, false, true})
+	table.SetColumnSeparator(" ")
+	table.SetCenterSeparator("-")
+
+	for _, l := range all {
+		if l.count() == 0 {
+			table.Append([]string{l.name, "0", "-", "-", "-", "-", "-"})
+			continue
+		}
+
+		table.Append([]string{
+			l.name,
+			strconv.Itoa(l.count()),
+			formatLatency(l.percentile(0)),
+			formatLatency(l.percentile(50)),
+			formatLatency(l.percentile(99)),
+			formatLatency(l.percentile(99.9)),
+			formatLatency(l.percentile(100)),
+		})
+	}
+
+	table.Render()
+}
+
+// writeHistogram prints the number of latencies in each bucket of the
+// Prometheus histograms along with a bar scaled to the fullest bucket.
+func writeHistogram(w io.Writer, l *latencies) {
+	const width = 40
+
+	counts := make([]int, len(latencyBuckets)+1)
+	for _, v := range l.values {
+		i := sort.SearchFloat64s(latencyBuckets, v.Seconds())
+		counts[i] = counts[i] + 1
+	}
+
+	max := 0
+	for _, c := range counts {
+		if c > max {
+			max = c
+		}
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{l.name, "COUNT", ""})
+	table.SetBorders(tablewriter.Border{false, false, false, true})
+	table.SetColumnSeparator(" ")
+	table.SetCenterSeparator("-")
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_LEFT})
+
+	for i, c := range counts {
+		var bucket string
+		if i < len(latencyBuckets) {
+			bucket = "<= " + formatLatency(time.Duration(latencyBuckets[i]*float64(time.Second)))
+		} else {
+			bucket = "> " + formatLatency(time.Duration(latencyBuckets[i-1]*float64(time.Second)))
+		}
+
+		bar := ""
+		if max > 0 {
+			bar = strings.Repeat("#", c*width/max)
+		}
+
+		table.Append([]string{bucket, strconv.Itoa(c), bar})
+	}
+
+	table.Render()
+}
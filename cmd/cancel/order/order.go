@@ -18,6 +18,7 @@ import (
 	"sylr.dev/fix/config"
 	"sylr.dev/fix/pkg/cli/complete"
 	"sylr.dev/fix/pkg/cli/options"
+	"sylr.dev/fix/pkg/daemon"
 	"sylr.dev/fix/pkg/dict"
 	"sylr.dev/fix/pkg/errors"
 	"sylr.dev/fix/pkg/initiator"
@@ -81,12 +82,11 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sessions, err := context.GetSessions()
+	session, err := context.GetSessionFor(enum.MsgType_ORDER_CANCEL_REQUEST, options.Session)
 	if err != nil {
 		return err
 	}
 
-	session := sessions[0]
 	initiatorConfig, err := context.GetInitiator()
 	if err != nil {
 		return err
@@ -97,7 +97,7 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	settings, err := context.ToQuickFixInitiatorSettings()
+	settings, err := context.ToQuickFixInitiatorSettings(session.Name)
 	if err != nil {
 		return err
 	}
@@ -123,41 +123,60 @@ func Execute(cmd *cobra.Command, args []string) error {
 		timeout = 5 * time.Second
 	}
 
-	init, err := initiator.Initiate(app, settings, quickfixLogger)
-	if err != nil {
-		return err
-	}
+	var send func(quickfix.Messagable) error
+	var messages <-chan *quickfix.Message
 
-	// Start session
-	if err = init.Start(); err != nil {
-		return err
-	}
+	// Send through fix daemon when it is running, it owns the sessions
+	if client, err := daemon.Dial(logger, options.DaemonSocket, transportDict, appDict); err == nil {
+		logger.Debug().Msgf("Sending through daemon: %s", options.DaemonSocket)
+		defer client.Close()
+
+		send = func(m quickfix.Messagable) error {
+			return client.Send(context.Name, *session, m)
+		}
+		messages = client.Messages()
+	} else {
+		init, err := initiator.Initiate(app, settings, quickfixLogger)
+		if err != nil {
+			return err
+		}
+
+		// Start session
+		if err = init.Start(); err != nil {
+			return err
+		}
+
+		defer func() {
+			app.Stop()
+			init.Stop()
+		}()
+
+		// Wait for session connection
+		var sessionId quickfix.SessionID
+		var ok bool
+		select {
+		case <-time.After(timeout):
+			return errors.ConnectionTimeout
+		case sessionId, ok = <-app.Connected:
+			if !ok {
+				return errors.FixLogout
+			}
+		}
 
-	defer func() {
-		app.Stop()
-		init.Stop()
-	}()
-
-	// Wait for session connection
-	var sessionId quickfix.SessionID
-	var ok bool
-	select {
-	case <-time.After(timeout):
-		return errors.ConnectionTimeout
-	case sessionId, ok = <-app.Connected:
-		if !ok {
-			return errors.FixLogout
+		send = func(m quickfix.Messagable) error {
+			return quickfix.SendToTarget(m, sessionId)
 		}
+		messages = app.FromAppMessages
 	}
 
 	// Prepare cancel message
-	cancelMsg, err := buildMessage(*session)
+	cancelMsg, err := BuildMessage(*session)
 	if err != nil {
 		return err
 	}
 
 	// Send the cancel message
-	err = quickfix.SendToTarget(cancelMsg, sessionId)
+	err = send(cancelMsg)
 	if err != nil {
 		return err
 	}
@@ -183,7 +202,7 @@ LOOP:
 			logger.Warn().Msgf("Timeout while expecting execution report or cancel request reject")
 			break LOOP
 
-		case msg, ok := <-app.FromAppMessages:
+		case msg, ok := <-messages:
 			if !ok {
 				break LOOP
 			}
@@ -201,7 +220,9 @@ LOOP:
 	return nil
 }
 
-func buildMessage(session config.Session) (quickfix.Messagable, error) {
+// BuildMessage builds the message of the command for the session from its
+// options, it is also used by fix shell.
+func BuildMessage(session config.Session) (quickfix.Messagable, error) {
 	eside, err := dict.OrderSideStringToEnum(optionOrderSide)
 	if err != nil {
 		return nil, err
@@ -72,12 +72,11 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sessions, err := context.GetSessions()
+	session, err := context.GetSessionFor(enum.MsgType_QUOTE_CANCEL, options.Session)
 	if err != nil {
 		return err
 	}
 
-	session := sessions[0]
 	initiatorConfig, err := context.GetInitiator()
 	if err != nil {
 		return err
@@ -88,7 +87,7 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	settings, err := context.ToQuickFixInitiatorSettings()
+	settings, err := context.ToQuickFixInitiatorSettings(session.Name)
 	if err != nil {
 		return err
 	}
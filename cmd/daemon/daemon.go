@@ -0,0 +1,62 @@
+package daemon
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/daemon"
+	"sylr.dev/fix/pkg/database"
+	"sylr.dev/fix/pkg/utils"
+)
+
+var DaemonCmd = &cobra.Command{
+	Use:               "daemon",
+	Short:             "Keep the sessions of all contexts open",
+	Long:              "Initiate the sessions of all contexts and send the messages of the other fix commands through them.",
+	Args:              cobra.ExactArgs(0),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	PersistentPreRunE: utils.MakePersistentPreRunE(database.ValidateOptions),
+	RunE:              Execute,
+}
+
+func init() {
+	options := config.GetOptions()
+
+	DaemonCmd.Flags().BoolVar(&options.QuickFixLogging, "quickfix-logging", false, "Enable quickfix logging")
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	options := config.GetOptions()
+	logger := config.GetLogger()
+
+	var quickfixLogger *zerolog.Logger
+	if options.QuickFixLogging {
+		quickfixLogger = logger
+	}
+
+	server, err := daemon.NewServer(logger, config.GetContexts(), quickfixLogger)
+	if err != nil {
+		return err
+	}
+
+	if err = server.Start(options.DaemonSocket); err != nil {
+		return err
+	}
+
+	defer server.Stop()
+
+	logger.Info().Msgf("Listening on %s", options.DaemonSocket)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	signal := <-interrupt
+	logger.Debug().Msgf("Received signal: %s", signal)
+
+	return nil
+}
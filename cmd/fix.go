@@ -13,15 +13,19 @@ import (
 	"github.com/spf13/cobra"
 
 	"sylr.dev/fix/cmd/amend"
+	"sylr.dev/fix/cmd/bench"
 	"sylr.dev/fix/cmd/cancel"
+	"sylr.dev/fix/cmd/daemon"
 	initcmd "sylr.dev/fix/cmd/init"
 	"sylr.dev/fix/cmd/initiator"
 	"sylr.dev/fix/cmd/list"
 	"sylr.dev/fix/cmd/marketdata"
 	"sylr.dev/fix/cmd/new"
 	"sylr.dev/fix/cmd/probe"
+	"sylr.dev/fix/cmd/shell"
 	"sylr.dev/fix/cmd/status"
 	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/admin"
 )
 
 var Version = "dev"
@@ -41,16 +45,20 @@ func init() {
 	options := config.GetOptions()
 
 	FixCmd.AddCommand(amend.AmendCmd)
+	FixCmd.AddCommand(bench.BenchCmd)
 	FixCmd.AddCommand(cancel.CancelCmd)
+	FixCmd.AddCommand(daemon.DaemonCmd)
 	FixCmd.AddCommand(initcmd.InitCmd)
 	FixCmd.AddCommand(initiator.InitiatorCmd)
 	FixCmd.AddCommand(list.ListCmd)
 	FixCmd.AddCommand(marketdata.MarketDataCmd)
 	FixCmd.AddCommand(new.NewCmd)
 	FixCmd.AddCommand(probe.ProbeCmd)
+	FixCmd.AddCommand(shell.ShellCmd)
 	FixCmd.AddCommand(status.StatusCmd)
 
 	configPath := filepath.Join("$HOME", ".fix", "config")
+	daemonSocket := filepath.Join("$HOME", ".fix", "daemon.sock")
 
 	FixCmd.PersistentFlags().StringVar(&options.Config, "config", os.ExpandEnv(configPath), "Config file")
 	FixCmd.PersistentFlags().CountVarP(&options.Verbose, "verbose", "v", "Increase verbosity")
@@ -60,7 +68,10 @@ func init() {
 	FixCmd.PersistentFlags().Bool("version", false, "Version for fix")
 	FixCmd.PersistentFlags().BoolVar(&options.Metrics, "metrics", false, "Enable metrics")
 	FixCmd.PersistentFlags().BoolVar(&options.PProf, "pprof", false, "Enable pprof")
+	FixCmd.PersistentFlags().BoolVar(&options.Admin, "admin", false, "Enable admin API")
+	FixCmd.PersistentFlags().StringVar(&options.AdminAddress, "admin-address", "localhost:8081", "Address the admin API listens on")
 	FixCmd.PersistentFlags().IntVar(&options.HTTPPort, "port", 8080, "HTTP port")
+	FixCmd.PersistentFlags().StringVar(&options.DaemonSocket, "daemon-socket", os.ExpandEnv(daemonSocket), "Socket of fix daemon, messages are sent through it when it is running (empty to disable)")
 }
 
 func InitLogger(cmd *cobra.Command, args []string) error {
@@ -83,6 +94,12 @@ func InitLogger(cmd *cobra.Command, args []string) error {
 func InitHTTP(cmd *cobra.Command, args []string) error {
 	options := config.GetOptions()
 
+	// The admin API steers sessions, it is not served alongside the metrics
+	// so that it can stay on the loopback interface.
+	if options.Admin {
+		go http.ListenAndServe(options.AdminAddress, admin.Handler())
+	}
+
 	if !options.Metrics && !options.PProf {
 		return nil
 	}
@@ -99,7 +116,6 @@ func InitHTTP(cmd *cobra.Command, args []string) error {
 		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	}
-
 	go http.ListenAndServe(fmt.Sprintf(":%d", options.HTTPPort), mux)
 
 	return nil
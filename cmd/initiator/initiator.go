@@ -60,7 +60,7 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sessions, err := context.GetSessions()
+	session, err := context.GetSessionFor(enum.MsgType_HEARTBEAT, options.Session)
 	if err != nil {
 		return err
 	}
@@ -70,12 +70,11 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	settings, err := context.ToQuickFixInitiatorSettings()
+	settings, err := context.ToQuickFixInitiatorSettings(session.Name)
 	if err != nil {
 		return err
 	}
 
-	session := sessions[0]
 	transportDict, appDict, err := session.GetFIXDictionaries()
 	if err != nil {
 		return err
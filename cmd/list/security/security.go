@@ -63,7 +63,7 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sessions, err := context.GetSessions()
+	session, err := context.GetSessionFor(enum.MsgType_SECURITY_LIST_REQUEST, options.Session)
 	if err != nil {
 		return err
 	}
@@ -73,13 +73,12 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	session := sessions[0]
 	transportDict, appDict, err := session.GetFIXDictionaries()
 	if err != nil {
 		return err
 	}
 
-	settings, err := context.ToQuickFixInitiatorSettings()
+	settings, err := context.ToQuickFixInitiatorSettings(session.Name)
 	if err != nil {
 		return err
 	}
@@ -110,7 +110,7 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sessions, err := context.GetSessions()
+	session, err := context.GetSessionFor(enum.MsgType_MARKET_DATA_REQUEST, options.Session)
 	if err != nil {
 		return err
 	}
@@ -120,13 +120,12 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	session := sessions[0]
 	transportDict, appDict, err := session.GetFIXDictionaries()
 	if err != nil {
 		return err
 	}
 
-	settings, err := context.ToQuickFixInitiatorSettings()
+	settings, err := context.ToQuickFixInitiatorSettings(session.Name)
 	if err != nil {
 		return err
 	}
@@ -179,7 +178,7 @@ func Execute(cmd *cobra.Command, args []string) error {
 	}
 
 	// Prepare securitylist
-	securitylist, err := buildMessage(*session)
+	securitylist, err := BuildMessage(*session)
 	if err != nil {
 		return err
 	}
@@ -210,7 +209,9 @@ LOOP:
 	return nil
 }
 
-func buildMessage(session config.Session) (quickfix.Messagable, error) {
+// BuildMessage builds the message of the command for the session from its
+// options, it is also used by fix shell.
+func BuildMessage(session config.Session) (quickfix.Messagable, error) {
 	mdReqID := field.NewMDReqID(optionMDReqID)
 	subReqType := field.NewSubscriptionRequestType(dict.SubscriptionRequestTypes[strings.ToUpper(optionSubType)])
 	marketDepth := field.NewMarketDepth(optionMarketDepth)
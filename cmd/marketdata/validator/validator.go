@@ -64,7 +64,7 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sessions, err := context.GetSessions()
+	session, err := context.GetSessionFor(enum.MsgType_MARKET_DATA_REQUEST, options.Session)
 	if err != nil {
 		return err
 	}
@@ -74,13 +74,12 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	session := sessions[0]
 	transportDict, appDict, err := session.GetFIXDictionaries()
 	if err != nil {
 		return err
 	}
 
-	settings, err := context.ToQuickFixInitiatorSettings()
+	settings, err := context.ToQuickFixInitiatorSettings(session.Name)
 	if err != nil {
 		return err
 	}
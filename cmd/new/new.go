@@ -4,6 +4,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"sylr.dev/fix/cmd/new/order"
+	"sylr.dev/fix/cmd/new/orders"
 	"sylr.dev/fix/cmd/new/quote"
 	"sylr.dev/fix/pkg/initiator"
 	"sylr.dev/fix/pkg/utils"
@@ -38,8 +39,10 @@ func init() {
 	initiator.AddPersistentFlags(NewCmd)
 	initiator.AddPersistentFlagCompletions(NewCmd)
 	initiator.AddPersistentFlagCompletions(neworder.NewOrderCmd)
+	initiator.AddPersistentFlagCompletions(neworders.NewOrdersCmd)
 	initiator.AddPersistentFlagCompletions(newquote.NewQuoteCmd)
 
 	NewCmd.AddCommand(neworder.NewOrderCmd)
+	NewCmd.AddCommand(neworders.NewOrdersCmd)
 	NewCmd.AddCommand(newquote.NewQuoteCmd)
 }
@@ -22,6 +22,7 @@ import (
 	"sylr.dev/fix/config"
 	"sylr.dev/fix/pkg/cli/complete"
 	"sylr.dev/fix/pkg/cli/options"
+	"sylr.dev/fix/pkg/daemon"
 	"sylr.dev/fix/pkg/dict"
 	"sylr.dev/fix/pkg/errors"
 	"sylr.dev/fix/pkg/initiator"
@@ -133,12 +134,13 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sessions, err := context.GetSessions()
+	// The order is sent on one session, the daemon also relays the execution
+	// reports of the other sessions of the context, e.g. a drop-copy one.
+	session, err := context.GetSessionFor(enum.MsgType_ORDER_SINGLE, options.Session)
 	if err != nil {
 		return err
 	}
 
-	session := sessions[0]
 	initiatorConfig, err := context.GetInitiator()
 	if err != nil {
 		return err
@@ -149,7 +151,7 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	settings, err := context.ToQuickFixInitiatorSettings()
+	settings, err := context.ToQuickFixInitiatorSettings(session.Name)
 	if err != nil {
 		return err
 	}
@@ -175,39 +177,56 @@ func Execute(cmd *cobra.Command, args []string) error {
 		timeout = 5 * time.Second
 	}
 
-	init, err := initiator.Initiate(app, settings, quickfixLogger)
-	if err != nil {
-		return err
-	}
+	var send func(quickfix.Messagable) error
+	var messages <-chan *quickfix.Message
 
-	// Start session
-	if err = init.Start(); err != nil {
-		return err
-	}
+	// Send through fix daemon when it is running, it owns the sessions
+	if client, err := daemon.Dial(logger, options.DaemonSocket, transportDict, appDict); err == nil {
+		logger.Debug().Msgf("Sending through daemon: %s", options.DaemonSocket)
+		defer client.Close()
 
-	defer func() {
-		app.Stop()
-		init.Stop()
-	}()
+		send = func(m quickfix.Messagable) error {
+			return client.Send(context.Name, *session, m)
+		}
+		messages = client.Messages()
+	} else {
+		init, err := initiator.Initiate(app, settings, quickfixLogger)
+		if err != nil {
+			return err
+		}
 
-	// Wait for session connection
-	select {
-	case <-time.After(timeout):
-		return errors.ConnectionTimeout
-	case _, ok := <-app.Connected:
-		if !ok {
-			return errors.FixLogout
+		// Start session
+		if err = init.Start(); err != nil {
+			return err
 		}
+
+		defer func() {
+			app.Stop()
+			init.Stop()
+		}()
+
+		// Wait for session connection
+		select {
+		case <-time.After(timeout):
+			return errors.ConnectionTimeout
+		case _, ok := <-app.Connected:
+			if !ok {
+				return errors.FixLogout
+			}
+		}
+
+		send = quickfix.Send
+		messages = app.FromAppMessages
 	}
 
 	// Prepare order
-	order, err := buildMessage(*session)
+	order, err := BuildMessage(*session)
 	if err != nil {
 		return err
 	}
 
 	// Send the order
-	err = quickfix.Send(order)
+	err = send(order)
 	if err != nil {
 		return err
 	}
@@ -230,6 +249,9 @@ func Execute(cmd *cobra.Command, args []string) error {
 
 	var lastExecutionReport *quickfix.Message
 
+	// ClOrdIDs of the order and of its updates
+	clOrdIDs := map[string]bool{optionOrderID: true}
+
 LOOP:
 	for {
 		select {
@@ -248,17 +270,34 @@ LOOP:
 				return err
 			}
 
+			if clOrdID, err := orderUpdateMsg.ToMessage().Body.GetString(tag.ClOrdID); err == nil {
+				clOrdIDs[clOrdID] = true
+			}
+
 			// Send the order
-			err = quickfix.Send(orderUpdateMsg)
+			err = send(orderUpdateMsg)
 			if err != nil {
 				return err
 			}
 
-		case msg, ok := <-app.FromAppMessages:
+		case msg, ok := <-messages:
 			if !ok {
 				break LOOP
 			}
 
+			// Only the responses to the order count, messages of the other
+			// sessions, e.g. drop copies, must carry one of its ClOrdIDs
+			fromSession := isFromSession(msg, *session)
+			if msg.Body.Has(tag.ClOrdID) {
+				clOrdID, _ := msg.Body.GetString(tag.ClOrdID)
+				origClOrdID, _ := msg.Body.GetString(tag.OrigClOrdID)
+				if !clOrdIDs[clOrdID] && !clOrdIDs[origClOrdID] {
+					continue LOOP
+				}
+			} else if !fromSession {
+				continue LOOP
+			}
+
 			if err := processResponse(app, msg); err != nil {
 				if errors.Is(err, quickfix.InvalidMessageType()) {
 					continue LOOP
@@ -267,6 +306,11 @@ LOOP:
 				return err
 			}
 
+			// Drop copies are only printed
+			if !fromSession {
+				continue LOOP
+			}
+
 			if msgType, err := msg.Header.GetString(tag.MsgType); err == nil && enum.MsgType(msgType) == enum.MsgType_EXECUTION_REPORT {
 				lastExecutionReport = msg
 			}
@@ -296,7 +340,9 @@ LOOP:
 	return nil
 }
 
-func buildMessage(session config.Session) (quickfix.Messagable, error) {
+// BuildMessage builds the message of the command for the session from its
+// options, it is also used by fix shell.
+func BuildMessage(session config.Session) (quickfix.Messagable, error) {
 	eside, err := dict.OrderSideStringToEnum(optionOrderSide)
 	if err != nil {
 		return nil, err
@@ -435,6 +481,14 @@ func buildCancelReplaceMessage(session config.Session, executionReport *quickfix
 	return message, nil
 }
 
+// isFromSession tells whether the message was received on the session.
+func isFromSession(msg *quickfix.Message, session config.Session) bool {
+	senderCompID, _ := msg.Header.GetString(tag.SenderCompID)
+	targetCompID, _ := msg.Header.GetString(tag.TargetCompID)
+
+	return senderCompID == session.TargetCompID && targetCompID == session.SenderCompID
+}
+
 func processResponse(app *application.NewOrder, msg *quickfix.Message) error {
 	msgType := field.MsgTypeField{}
 	ordStatus := field.OrdStatusField{}
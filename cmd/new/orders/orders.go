@@ -0,0 +1,530 @@
+package neworders
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/olekukonko/tablewriter"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	yaml "sylr.dev/yaml/v3"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/tag"
+
+	neworder "sylr.dev/fix/cmd/new/order"
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/daemon"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/initiator"
+	"sylr.dev/fix/pkg/initiator/application"
+	"sylr.dev/fix/pkg/utils"
+)
+
+var (
+	optionFile               string
+	optionRate               float64
+	optionExecReports        int
+	optionExecReportsTimeout time.Duration
+)
+
+var NewOrdersCmd = &cobra.Command{
+	Use:               "orders",
+	Short:             "New single orders from a file",
+	Long:              "Send the new single orders of a CSV or YAML file after initiating a session with a FIX acceptor.",
+	Args:              cobra.ExactArgs(0),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	PersistentPreRunE: utils.MakePersistentPreRunE(Validate),
+	RunE:              Execute,
+}
+
+func init() {
+	NewOrdersCmd.Flags().StringVar(&optionFile, "file", "", "CSV or YAML file of orders, columns are the flags of fix new order")
+	NewOrdersCmd.Flags().Float64Var(&optionRate, "rate", 0, "Orders sent per second (0 sends them all at once)")
+	NewOrdersCmd.Flags().IntVar(&optionExecReports, "exec-reports", 1, "Expect given number of execution reports per order before logging out (0 wait indefinitely)")
+	NewOrdersCmd.Flags().DurationVar(&optionExecReportsTimeout, "exec-reports-timeout", 5*time.Second, "Log out if no execution report is received within timeout once all orders are sent (0s wait indefinitely)")
+
+	NewOrdersCmd.MarkFlagRequired("file")
+
+	NewOrdersCmd.RegisterFlagCompletionFunc("file", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"csv", "yaml", "yml"}, cobra.ShellCompDirectiveFilterFileExt
+	})
+}
+
+func Validate(cmd *cobra.Command, args []string) error {
+	if optionRate < 0 {
+		return fmt.Errorf("%w: --rate can't be negative", errors.Options)
+	}
+
+	switch strings.ToLower(filepath.Ext(optionFile)) {
+	case ".csv", ".yaml", ".yml":
+	default:
+		return fmt.Errorf("%w: %s", errors.OptionsUnsupportedFileFormat, optionFile)
+	}
+
+	return nil
+}
+
+// row holds the values of the fix new order flags an order is sent with.
+type row map[string][]string
+
+// order is an order of the file and the messages received about it.
+type order struct {
+	clOrdID  string
+	symbol   string
+	side     string
+	message  *quickfix.Message
+	reports  []*quickfix.Message
+	rejected bool
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	options := config.GetOptions()
+	logger := config.GetLogger()
+
+	context, err := config.GetCurrentContext()
+	if err != nil {
+		return err
+	}
+
+	session, err := context.GetSessionFor(enum.MsgType_ORDER_SINGLE, options.Session)
+	if err != nil {
+		return err
+	}
+
+	initiatorConfig, err := context.GetInitiator()
+	if err != nil {
+		return err
+	}
+
+	transportDict, appDict, err := session.GetFIXDictionaries()
+	if err != nil {
+		return err
+	}
+
+	settings, err := context.ToQuickFixInitiatorSettings(session.Name)
+	if err != nil {
+		return err
+	}
+
+	// Build all the orders before logging on so that an invalid line does not
+	// leave the file half sent
+	rows, err := readRows(optionFile)
+	if err != nil {
+		return err
+	}
+
+	orders, err := buildOrders(rows, *session)
+	if err != nil {
+		return err
+	}
+
+	app := application.NewNewOrder()
+	app.Logger = logger
+	app.Settings = settings
+	app.TransportDataDictionary = transportDict
+	app.AppDataDictionary = appDict
+
+	var quickfixLogger *zerolog.Logger
+	if options.QuickFixLogging {
+		quickfixLogger = logger
+	}
+
+	// Choose right timeout cli option > config > default value (5s)
+	var timeout time.Duration
+	if options.Timeout != time.Duration(0) {
+		timeout = options.Timeout
+	} else if initiatorConfig.SocketTimeout != time.Duration(0) {
+		timeout = initiatorConfig.SocketTimeout
+	} else {
+		timeout = 5 * time.Second
+	}
+
+	var send func(quickfix.Messagable) error
+	var messages <-chan *quickfix.Message
+
+	// Send through fix daemon when it is running, it owns the sessions
+	if client, err := daemon.Dial(logger, options.DaemonSocket, transportDict, appDict); err == nil {
+		logger.Debug().Msgf("Sending through daemon: %s", options.DaemonSocket)
+		defer client.Close()
+
+		send = func(m quickfix.Messagable) error {
+			return client.Send(context.Name, *session, m)
+		}
+		messages = client.Messages()
+	} else {
+		init, err := initiator.Initiate(app, settings, quickfixLogger)
+		if err != nil {
+			return err
+		}
+
+		// Start session
+		if err = init.Start(); err != nil {
+			return err
+		}
+
+		defer func() {
+			app.Stop()
+			init.Stop()
+		}()
+
+		// Wait for session connection
+		select {
+		case <-time.After(timeout):
+			return errors.ConnectionTimeout
+		case _, ok := <-app.Connected:
+			if !ok {
+				return errors.FixLogout
+			}
+		}
+
+		send = quickfix.Send
+		messages = app.FromAppMessages
+	}
+
+	byClOrdID := make(map[string]*order, len(orders))
+	for _, o := range orders {
+		byClOrdID[o.clOrdID] = o
+	}
+
+	// Session level rejects reference the sequence number of the order
+	bySeqNum := make(map[int]*order, len(orders))
+
+	sent := 0
+	sendNext := func() error {
+		o := orders[sent]
+		o.message.Body.Set(field.NewTransactTime(time.Now()))
+		sent = sent + 1
+
+		if err := send(o.message); err != nil {
+			return err
+		}
+
+		if seqNum, err := o.message.Header.GetInt(tag.MsgSeqNum); err == nil {
+			bySeqNum[seqNum] = o
+		}
+
+		return nil
+	}
+
+	// Orders are paced by a ticker, the timeout starts once they are all sent
+	var pace <-chan time.Time
+	if optionRate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / optionRate))
+		defer ticker.Stop()
+		pace = ticker.C
+
+		if err = sendNext(); err != nil {
+			return err
+		}
+	} else {
+		for sent < len(orders) {
+			if err = sendNext(); err != nil {
+				return err
+			}
+		}
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	waitTimeout := make(<-chan time.Time)
+	resetTimeout := func() {
+		if sent == len(orders) && optionExecReportsTimeout > 0 {
+			waitTimeout = time.After(optionExecReportsTimeout)
+		}
+	}
+	resetTimeout()
+
+	sessionRejects := 0
+
+LOOP:
+	for {
+		select {
+		case signal := <-interrupt:
+			logger.Debug().Msgf("Received signal: %s", signal)
+			break LOOP
+
+		case <-waitTimeout:
+			logger.Warn().Msgf("Timeout while expecting execution reports")
+			break LOOP
+
+		case <-pace:
+			if err = sendNext(); err != nil {
+				return err
+			}
+			if sent == len(orders) {
+				pace = nil
+				resetTimeout()
+			}
+
+		case msg, ok := <-messages:
+			if !ok {
+				break LOOP
+			}
+
+			msgType, err := msg.Header.GetString(tag.MsgType)
+			if err != nil {
+				return err
+			}
+
+			switch enum.MsgType(msgType) {
+			case enum.MsgType_EXECUTION_REPORT:
+				clOrdID, _ := msg.Body.GetString(tag.ClOrdID)
+				if o, ok := byClOrdID[clOrdID]; ok {
+					o.reports = append(o.reports, msg)
+					if ordStatus, _ := msg.Body.GetString(tag.OrdStatus); enum.OrdStatus(ordStatus) == enum.OrdStatus_REJECTED {
+						o.rejected = true
+					}
+				}
+
+			case enum.MsgType_BUSINESS_MESSAGE_REJECT:
+				refID, _ := msg.Body.GetString(tag.BusinessRejectRefID)
+				if o, ok := byClOrdID[refID]; ok {
+					o.reports = append(o.reports, msg)
+					o.rejected = true
+				}
+
+			case enum.MsgType_REJECT:
+				refSeqNum, _ := msg.Body.GetInt(tag.RefSeqNum)
+				if o, ok := bySeqNum[refSeqNum]; ok {
+					o.reports = append(o.reports, msg)
+					o.rejected = true
+					break
+				}
+
+				// Rejects of other messages are not counted as order rejects
+				text, _ := msg.Body.GetString(tag.Text)
+				logger.Error().Msgf("Message rejected: %s", text)
+				sessionRejects = sessionRejects + 1
+
+			default:
+				continue LOOP
+			}
+
+			resetTimeout()
+		}
+
+		if sent == len(orders) && optionExecReports != 0 && complete(orders) {
+			logger.Debug().Msgf("Exiting response loop, all orders got %d execution reports", optionExecReports)
+			break LOOP
+		}
+	}
+
+	writeReportsTable(orders, transportDict, appDict)
+
+	rejects := 0
+	for _, o := range orders {
+		if o.rejected {
+			rejects = rejects + 1
+		}
+	}
+
+	if rejects > 0 {
+		return fmt.Errorf("%w: %d out of %d orders", errors.FixOrderRejected, rejects, len(orders))
+	}
+
+	if sessionRejects > 0 {
+		return fmt.Errorf("%w: %d messages", errors.FixMessageRejected, sessionRejects)
+	}
+
+	return nil
+}
+
+// complete tells whether all orders got the expected execution reports or
+// were rejected.
+func complete(orders []*order) bool {
+	for _, o := range orders {
+		if !o.rejected && len(o.reports) < optionExecReports {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readRows reads the orders of a CSV file, whose header gives the flag of each
+// column, or of a YAML file holding a list of flag maps. Slice flags are
+// given several values with a YAML list or comma separated values.
+func readRows(path string) ([]row, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []row
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		records, err := csv.NewReader(bytes.NewBuffer(file)).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(records) == 0 {
+			return nil, nil
+		}
+
+		header := records[0]
+		for _, record := range records[1:] {
+			r := make(row)
+			for k, value := range record {
+				if len(value) > 0 {
+					r[strings.TrimSpace(header[k])] = []string{value}
+				}
+			}
+			rows = append(rows, r)
+		}
+
+	case ".yaml", ".yml":
+		var docs []map[string]interface{}
+		if err = yaml.NewDecoder(bytes.NewBuffer(file)).Decode(&docs); err != nil {
+			return nil, err
+		}
+
+		for _, doc := range docs {
+			r := make(row)
+			for name, value := range doc {
+				switch v := value.(type) {
+				case nil:
+				case []interface{}:
+					for _, item := range v {
+						r[name] = append(r[name], fmt.Sprint(item))
+					}
+				default:
+					r[name] = []string{fmt.Sprint(v)}
+				}
+			}
+			rows = append(rows, r)
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: %s", errors.OptionsUnsupportedFileFormat, path)
+	}
+
+	return rows, nil
+}
+
+// buildOrders builds the message of each row with fix new order, its flags
+// are set from the row as if they had been given on the command line.
+func buildOrders(rows []row, session config.Session) ([]*order, error) {
+	flags := neworder.NewOrderCmd.Flags()
+	orders := make([]*order, 0, len(rows))
+	clOrdIDs := make(map[string]struct{}, len(rows))
+
+	for i, r := range rows {
+		utils.ResetFlags(neworder.NewOrderCmd)
+
+		for name, values := range r {
+			if flags.Lookup(name) == nil {
+				return nil, fmt.Errorf("%w: order %d: unknown column %s", errors.Options, i+1, name)
+			}
+			for _, value := range values {
+				if err := flags.Set(name, value); err != nil {
+					return nil, fmt.Errorf("order %d: %w", i+1, err)
+				}
+			}
+		}
+
+		utils.RestoreSliceDefaults(flags)
+
+		if err := utils.ValidateRequiredFlags(neworder.NewOrderCmd); err != nil {
+			return nil, fmt.Errorf("order %d: %w", i+1, err)
+		}
+
+		if err := neworder.Validate(neworder.NewOrderCmd, nil); err != nil {
+			return nil, fmt.Errorf("order %d: %w", i+1, err)
+		}
+
+		message, err := neworder.BuildMessage(session)
+		if err != nil {
+			return nil, fmt.Errorf("order %d: %w", i+1, err)
+		}
+
+		o := order{message: message.ToMessage()}
+		o.clOrdID, _ = o.message.Body.GetString(tag.ClOrdID)
+		o.symbol, _ = o.message.Body.GetString(tag.Symbol)
+		o.side, _ = o.message.Body.GetString(tag.Side)
+
+		if _, ok := clOrdIDs[o.clOrdID]; ok {
+			return nil, fmt.Errorf("%w: order %d: duplicate id %s", errors.OptionsInconsistentValues, i+1, o.clOrdID)
+		}
+		clOrdIDs[o.clOrdID] = struct{}{}
+
+		orders = append(orders, &o)
+	}
+
+	return orders, nil
+}
+
+// writeReportsTable prints a line for each message received about each order,
+// orders without any keep a line of their own.
+func writeReportsTable(orders []*order, transportDict, appDict *datadictionary.DataDictionary) {
+	describe := func(t quickfix.Tag, value string) string {
+		for _, dict := range []*datadictionary.DataDictionary{appDict, transportDict} {
+			if dict == nil {
+				continue
+			}
+			if tagField, ok := dict.FieldTypeByTag[int(t)]; ok {
+				if e, ok := tagField.Enums[value]; ok {
+					return strcase.ToCamel(strings.ToLower(e.Description))
+				}
+			}
+		}
+		return value
+	}
+
+	get := func(part *quickfix.FieldMap, t quickfix.Tag) string {
+		value, err := part.GetString(t)
+		if err != nil {
+			return "-"
+		}
+		return value
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"CLORDID", "SYMBOL", "SIDE", "MESSAGE", "EXEC TYPE", "ORD STATUS", "CUM QTY", "LEAVES QTY", "TEXT"})
+	table.SetBorders(tablewriter.Border{false, false, false, true})
+	table.SetColumnSeparator(" ")
+	table.SetCenterSeparator("-")
+
+	for _, o := range orders {
+		side := describe(tag.Side, o.side)
+
+		if len(o.reports) == 0 {
+			table.Append([]string{o.clOrdID, o.symbol, side, "-", "-", "-", "-", "-", "-"})
+			continue
+		}
+
+		for _, msg := range o.reports {
+			msgType := get(&msg.Header.FieldMap, tag.MsgType)
+			execType := get(&msg.Body.FieldMap, tag.ExecType)
+			ordStatus := get(&msg.Body.FieldMap, tag.OrdStatus)
+
+			table.Append([]string{
+				o.clOrdID,
+				o.symbol,
+				side,
+				describe(tag.MsgType, msgType),
+				describe(tag.ExecType, execType),
+				describe(tag.OrdStatus, ordStatus),
+				get(&msg.Body.FieldMap, tag.CumQty),
+				get(&msg.Body.FieldMap, tag.LeavesQty),
+				get(&msg.Body.FieldMap, tag.Text),
+			})
+		}
+	}
+
+	table.Render()
+}
@@ -0,0 +1,427 @@
+package shell
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+
+	amendorder "sylr.dev/fix/cmd/amend/order"
+	cancelorder "sylr.dev/fix/cmd/cancel/order"
+	marketdatarequest "sylr.dev/fix/cmd/marketdata/request"
+	neworder "sylr.dev/fix/cmd/new/order"
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/initiator"
+	"sylr.dev/fix/pkg/initiator/application"
+	"sylr.dev/fix/pkg/utils"
+)
+
+var ShellCmd = &cobra.Command{
+	Use:               "shell",
+	Short:             "Interactive FIX shell",
+	Long:              "Log on the sessions of the context once and send messages over them interactively.",
+	Args:              cobra.ExactArgs(0),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	PersistentPreRunE: utils.MakePersistentPreRunE(initiator.ValidateOptions),
+	RunE:              Execute,
+}
+
+func init() {
+	initiator.AddPersistentFlags(ShellCmd)
+	initiator.AddPersistentFlagCompletions(ShellCmd)
+}
+
+// console reads the lines typed in the shell and prints its output.
+type console interface {
+	io.Writer
+	ReadLine() (string, error)
+}
+
+// lineReader is the console of a shell whose input is not a terminal, e.g. a
+// script piped to it.
+type lineReader struct {
+	io.Writer
+	scanner *bufio.Scanner
+}
+
+func (r *lineReader) ReadLine() (string, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+// shell runs the commands typed by the user over the sessions of the context.
+type shell struct {
+	context *config.Context
+	session string
+	app     *application.Shell
+	root    *cobra.Command
+	out     io.Writer
+	exit    bool
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	options := config.GetOptions()
+	logger := config.GetLogger()
+
+	context, err := config.GetCurrentContext()
+	if err != nil {
+		return err
+	}
+
+	sessions, err := context.GetSessions()
+	if err != nil {
+		return err
+	}
+
+	initiatorConfig, err := context.GetInitiator()
+	if err != nil {
+		return err
+	}
+
+	transportDict, appDict, err := sessions[0].GetFIXDictionaries()
+	if err != nil {
+		return err
+	}
+
+	settings, err := context.ToQuickFixInitiatorSettings()
+	if err != nil {
+		return err
+	}
+
+	// Line editing, history and completion are only available on terminals
+	var cons console
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer term.Restore(fd, state)
+
+		t := term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{os.Stdin, os.Stdout}, "fix> ")
+		if width, height, err := term.GetSize(fd); err == nil {
+			t.SetSize(width, height)
+		}
+		cons = t
+	} else {
+		cons = &lineReader{Writer: os.Stdout, scanner: bufio.NewScanner(os.Stdin)}
+	}
+
+	// Logs go through the console so that they do not mangle the prompt
+	shellLogger := logger.Output(zerolog.ConsoleWriter{
+		Out:        cons,
+		TimeFormat: "Jan 2 15:04:05.000-0700",
+	})
+
+	app := application.NewShell()
+	app.Logger = &shellLogger
+	app.Settings = settings
+	app.TransportDataDictionary = transportDict
+	app.AppDataDictionary = appDict
+
+	var quickfixLogger *zerolog.Logger
+	if options.QuickFixLogging {
+		quickfixLogger = &shellLogger
+	}
+
+	// Choose right timeout cli option > config > default value (5s)
+	var timeout time.Duration
+	if options.Timeout != time.Duration(0) {
+		timeout = options.Timeout
+	} else if initiatorConfig.SocketTimeout != time.Duration(0) {
+		timeout = initiatorConfig.SocketTimeout
+	} else {
+		timeout = 5 * time.Second
+	}
+
+	init, err := initiator.Initiate(app, settings, quickfixLogger)
+	if err != nil {
+		return err
+	}
+
+	// Start sessions
+	if err = init.Start(); err != nil {
+		return err
+	}
+
+	defer func() {
+		app.Stop()
+		init.Stop()
+	}()
+
+	// Wait for the connection of all sessions
+	deadline := time.After(timeout)
+	for range settings.SessionSettings() {
+		select {
+		case <-deadline:
+			return errors.ConnectionTimeout
+		case sessionID := <-app.Connected:
+			fmt.Fprintf(cons, "Logon: %s\n", sessionID)
+		case sessionID := <-app.Disconnected:
+			return fmt.Errorf("%w: %s", errors.FixLogout, sessionID)
+		}
+	}
+
+	s := &shell{
+		context: context,
+		session: options.Session,
+		app:     app,
+		out:     cons,
+	}
+	s.root = s.commands()
+
+	if t, ok := cons.(*term.Terminal); ok {
+		t.AutoCompleteCallback = s.complete
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go s.print(done)
+
+	for !s.exit {
+		line, err := cons.ReadLine()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		s.run(strings.Fields(line))
+	}
+
+	return nil
+}
+
+// commands returns the commands of the shell, they mirror the fix commands and
+// share their flags.
+func (s *shell) commands() *cobra.Command {
+	root := &cobra.Command{
+		Use:               "shell",
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+	}
+	root.SetOut(s.out)
+	root.SetErr(s.out)
+
+	newCmd := &cobra.Command{Use: "new", Short: "Send a new FIX message"}
+	newCmd.AddCommand(s.messageCommand("order", neworder.NewOrderCmd, enum.MsgType_ORDER_SINGLE, neworder.Validate, neworder.BuildMessage))
+
+	amendCmd := &cobra.Command{Use: "amend", Short: "Amend a FIX order"}
+	amendCmd.AddCommand(s.messageCommand("order", amendorder.AmendOrderCmd, enum.MsgType_ORDER_CANCEL_REPLACE_REQUEST, amendorder.Validate, amendorder.BuildMessage))
+
+	cancelCmd := &cobra.Command{Use: "cancel", Short: "Cancel a FIX order"}
+	cancelCmd.AddCommand(s.messageCommand("order", cancelorder.CancelOrderCmd, enum.MsgType_ORDER_CANCEL_REQUEST, cancelorder.Validate, cancelorder.BuildMessage))
+
+	mdCmd := &cobra.Command{Use: "md", Short: "Subscribe to market data"}
+	mdCmd.AddCommand(s.messageCommand("subscribe", marketdatarequest.MarketDataRequestCmd, enum.MsgType_MARKET_DATA_REQUEST,
+		func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("sub-type") {
+				cmd.Flags().Lookup("sub-type").Value.Set("snapshot_plus_updates")
+			}
+			return marketdatarequest.Validate(cmd, args)
+		}, marketdatarequest.BuildMessage))
+	mdCmd.AddCommand(s.messageCommand("unsubscribe", marketdatarequest.MarketDataRequestCmd, enum.MsgType_MARKET_DATA_REQUEST,
+		func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("id") {
+				return fmt.Errorf("%w: --id of the subscription is required", errors.Options)
+			}
+			cmd.Flags().Lookup("sub-type").Value.Set("disable_previous_snapshot_plus_update_request")
+			return marketdatarequest.Validate(cmd, args)
+		}, marketdatarequest.BuildMessage))
+
+	exitCmd := &cobra.Command{
+		Use:     "exit",
+		Aliases: []string{"quit"},
+		Short:   "Log out and leave the shell",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			s.exit = true
+		},
+	}
+
+	root.AddCommand(newCmd, amendCmd, cancelCmd, mdCmd, exitCmd)
+
+	return root
+}
+
+// messageCommand returns a shell command sending the message the mirrored fix
+// command builds on the session routed for msgType.
+func (s *shell) messageCommand(use string, mirror *cobra.Command, msgType enum.MsgType, validate func(*cobra.Command, []string) error, build func(config.Session) (quickfix.Messagable, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               use,
+		Short:             mirror.Short,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			utils.RestoreSliceDefaults(cmd.Flags())
+			return validate(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session, err := s.context.GetSessionFor(msgType, s.session)
+			if err != nil {
+				return err
+			}
+
+			message, err := build(*session)
+			if err != nil {
+				return err
+			}
+
+			if err = quickfix.Send(message); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(s.out, "-> %s sent on %s\n", messageName(message.ToMessage()), session.Name)
+
+			return nil
+		},
+	}
+	cmd.Flags().AddFlagSet(mirror.Flags())
+
+	return cmd
+}
+
+// run executes the command typed by the user. Flags are shared with the fix
+// commands and keep their values, they are reset before each command.
+func (s *shell) run(args []string) {
+	if len(args) == 0 {
+		return
+	}
+
+	utils.ResetFlags(s.root)
+	s.root.SetArgs(args)
+	if err := s.root.Execute(); err != nil {
+		fmt.Fprintf(s.out, "Error: %s\n", err)
+	}
+}
+
+// complete is the terminal completion callback. It completes the word before
+// the cursor with the choices cobra gives for the command line, the choices
+// are printed if there are several of them.
+func (s *shell) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	prefix := line[:pos]
+	words := strings.Fields(prefix)
+
+	var toComplete string
+	if len(words) > 0 && !strings.HasSuffix(prefix, " ") {
+		toComplete = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	choices := s.completions(words, toComplete)
+	if len(choices) == 0 {
+		return "", 0, false
+	}
+
+	completion := commonPrefix(choices)
+	if len(choices) == 1 {
+		completion += " "
+	} else if completion == toComplete {
+		// The terminal is locked until the callback returns
+		go fmt.Fprintln(s.out, strings.Join(choices, "  "))
+		return "", 0, false
+	}
+
+	prefix = prefix[:len(prefix)-len(toComplete)] + completion
+
+	return prefix + line[pos:], len(prefix), true
+}
+
+// completions runs the hidden completion command of cobra which calls the
+// completion functions of the flags.
+func (s *shell) completions(args []string, toComplete string) []string {
+	var out bytes.Buffer
+
+	utils.ResetFlags(s.root)
+	s.root.SetOut(&out)
+	s.root.SetErr(io.Discard)
+	s.root.SetArgs(append(append([]string{cobra.ShellCompNoDescRequestCmd}, args...), toComplete))
+	_ = s.root.Execute()
+	s.root.SetOut(s.out)
+	s.root.SetErr(s.out)
+
+	var choices []string
+	for _, choice := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(choice, ":") {
+			break
+		}
+		if len(choice) > 0 && strings.HasPrefix(choice, toComplete) {
+			choices = append(choices, choice)
+		}
+	}
+
+	return choices
+}
+
+// print writes the messages received and the logons and logouts of the
+// sessions until done is closed.
+func (s *shell) print(done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case sessionID := <-s.app.Connected:
+			fmt.Fprintf(s.out, "Logon: %s\n", sessionID)
+		case sessionID := <-s.app.Disconnected:
+			fmt.Fprintf(s.out, "Logout: %s\n", sessionID)
+		case message := <-s.app.FromAppMessages:
+			// Write the table at once so that the prompt is only redrawn
+			// once
+			var buf bytes.Buffer
+			fmt.Fprintf(&buf, "<- %s\n", messageName(message))
+			s.app.WriteMessageBodyAsTable(&buf, message)
+			s.out.Write(buf.Bytes())
+		}
+	}
+}
+
+// messageName returns the name of the type of the message followed by its
+// value.
+func messageName(message *quickfix.Message) string {
+	typ, ferr := message.MsgType()
+	if ferr != nil {
+		return "unknown message"
+	}
+
+	name, err := dict.SearchValue(dict.MessageTypes, enum.MsgType(typ))
+	if err != nil {
+		return typ
+	}
+
+	return fmt.Sprintf("%s(%s)", name, typ)
+}
+
+// commonPrefix returns the longest prefix of the choices.
+func commonPrefix(choices []string) string {
+	prefix := choices[0]
+	for _, choice := range choices[1:] {
+		for !strings.HasPrefix(choice, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
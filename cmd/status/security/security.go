@@ -94,12 +94,11 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sessions, err := context.GetSessions()
+	session, err := context.GetSessionFor(enum.MsgType_SECURITY_STATUS_REQUEST, options.Session)
 	if err != nil {
 		return err
 	}
 
-	session := sessions[0]
 	initiatior, err := context.GetInitiator()
 	if err != nil {
 		return err
@@ -110,7 +109,7 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	settings, err := context.ToQuickFixInitiatorSettings()
+	settings, err := context.ToQuickFixInitiatorSettings(session.Name)
 	if err != nil {
 		return err
 	}
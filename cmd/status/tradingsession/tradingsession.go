@@ -77,12 +77,11 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sessions, err := context.GetSessions()
+	session, err := context.GetSessionFor(enum.MsgType_TRADING_SESSION_STATUS_REQUEST, options.Session)
 	if err != nil {
 		return err
 	}
 
-	session := sessions[0]
 	initiatior, err := context.GetInitiator()
 	if err != nil {
 		return err
@@ -93,7 +92,7 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	settings, err := context.ToQuickFixInitiatorSettings()
+	settings, err := context.ToQuickFixInitiatorSettings(session.Name)
 	if err != nil {
 		return err
 	}
@@ -2,11 +2,14 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"time"
 
+	"github.com/quickfixgo/enum"
 	"github.com/quickfixgo/quickfix"
 	qconfig "github.com/quickfixgo/quickfix/config"
 	"github.com/quickfixgo/quickfix/datadictionary"
@@ -15,6 +18,32 @@ import (
 	"sylr.dev/fix/pkg/utils"
 )
 
+// Settings holding the CompID patterns of the dynamic sessions of an acceptor.
+const (
+	DynamicSenderCompIDSetting = "DynamicSenderCompID"
+	DynamicTargetCompIDSetting = "DynamicTargetCompID"
+)
+
+// DropCopySetting marks drop-copy acceptor sessions.
+const DropCopySetting = "DropCopy"
+
+// PostTradeSetting marks acceptor sessions receiving every trade capture
+// report.
+const PostTradeSetting = "PostTrade"
+
+// Settings holding the cancel-on-disconnect policy of acceptor sessions.
+const (
+	CancelOnDisconnectSetting      = "CancelOnDisconnect"
+	CancelOnDisconnectGraceSetting = "CancelOnDisconnectGrace"
+)
+
+// Settings holding the throttle of acceptor sessions.
+const (
+	ThrottleRateSetting   = "ThrottleRate"
+	ThrottleBurstSetting  = "ThrottleBurst"
+	ThrottleActionSetting = "ThrottleAction"
+)
+
 var (
 	fixDict = make(map[string]*datadictionary.DataDictionary)
 	options = cliOptions{}
@@ -104,7 +133,10 @@ type cliOptions struct {
 	QuickFixLogging bool
 	Metrics         bool
 	PProf           bool
+	Admin           bool
+	AdminAddress    string
 	HTTPPort        int
+	DaemonSocket    string
 }
 
 type fixConfig struct {
@@ -157,6 +189,17 @@ type Context struct {
 	Initiator string   `yaml:"initiator"`
 	Acceptor  string   `yaml:"acceptor"`
 	Sessions  []string `yaml:"sessions"`
+
+	// Acceptor sessions receiving a copy of the execution reports sent on the
+	// other sessions.
+	DropCopySessions []string `yaml:"drop-copy-sessions"`
+
+	// Acceptor sessions receiving a trade capture report for every trade.
+	PostTradeSessions []string `yaml:"post-trade-sessions"`
+
+	// Initiator sessions messages are sent on by MsgType, messages of the
+	// other types are sent on the first session of the context.
+	Routes map[string]string `yaml:"routes"`
 }
 
 func (c *Context) GetName() string {
@@ -231,9 +274,108 @@ func (c *common) setQuickFixGlobalSettings(globalSettings *quickfix.SessionSetti
 type Acceptor struct {
 	common `yaml:",inline"`
 
-	SocketAcceptHost string `yaml:"SocketAcceptHost"`
-	SocketAcceptPort int    `yaml:"SocketAcceptPort"`
-	UseTCPProxy      string `yaml:"UseTCPProxy"`
+	SocketAcceptHost  string  `yaml:"SocketAcceptHost"`
+	SocketAcceptPort  int     `yaml:"SocketAcceptPort"`
+	UseTCPProxy       string  `yaml:"UseTCPProxy"`
+	ReferenceDataFile string  `yaml:"ReferenceDataFile"`
+	RulesFile         string  `yaml:"RulesFile"`
+	RiskLimitsFile    string  `yaml:"RiskLimitsFile"`
+	FillMode          string  `yaml:"FillMode"`
+	StopTrigger       string  `yaml:"StopTrigger"`
+	Sinks             []*Sink `yaml:"Sinks"`
+
+	DynamicSessions *DynamicSessions `yaml:"DynamicSessions"`
+	Throttle        *Throttle        `yaml:"Throttle"`
+}
+
+// Throttle is a token bucket limiting the rate of the application messages an
+// acceptor session receives. Action is what happens to the messages exceeding
+// it: reject, queue or logout.
+type Throttle struct {
+	Rate   float64 `yaml:"Rate"`
+	Burst  int     `yaml:"Burst"`
+	Action string  `yaml:"Action"`
+}
+
+// Validate checks the throttle action, an empty one means reject.
+func (t *Throttle) Validate() error {
+	if t == nil {
+		return nil
+	}
+
+	switch t.Action {
+	case "", "reject", "queue", "logout":
+	default:
+		return fmt.Errorf("%w: unknown action %s", errors.ConfigInvalidThrottle, t.Action)
+	}
+
+	if t.Rate < 0 || t.Burst < 0 {
+		return fmt.Errorf("%w: negative rate or burst", errors.ConfigInvalidThrottle)
+	}
+
+	return nil
+}
+
+// validateQueue checks that messages queued by the throttle of a session with
+// the given heartbeat interval wait less than it, the session being held
+// while they wait. It is a no-op for the other actions or an unknown interval.
+func (t *Throttle) validateQueue(heartBtInt int) error {
+	if t == nil || t.Action != "queue" || t.Rate <= 0 || heartBtInt <= 0 {
+		return nil
+	}
+
+	burst := math.Max(float64(t.Burst), 1)
+	if burst/t.Rate >= float64(heartBtInt) {
+		return fmt.Errorf("%w: queueing %g messages at %g per second outlasts the heartbeat interval", errors.ConfigInvalidThrottle, burst, t.Rate)
+	}
+
+	return nil
+}
+
+// sessionThrottle returns the throttle of the session, its own or the one of
+// the acceptor.
+func (a *Acceptor) sessionThrottle(session *Session) *Throttle {
+	if session.Throttle != nil {
+		return session.Throttle
+	}
+	return a.Throttle
+}
+
+// DynamicSessions lets an acceptor create sessions on the fly for counterparties
+// which are not listed in its context. Dynamic sessions get the settings of the
+// template session, its CompIDs are ignored. The CompIDs of dynamic sessions can
+// be restricted with regular expressions.
+type DynamicSessions struct {
+	Session      string `yaml:"Session"`
+	SenderCompID string `yaml:"SenderCompID"`
+	TargetCompID string `yaml:"TargetCompID"`
+}
+
+// Validate checks that the template session exists and that the CompID
+// patterns compile.
+func (d *DynamicSessions) Validate() error {
+	if _, err := GetSession(d.Session); err != nil {
+		return err
+	}
+	for _, pattern := range []string{d.SenderCompID, d.TargetCompID} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%w: %s", errors.ConfigInvalidCompIDPattern, err)
+		}
+	}
+	return nil
+}
+
+// Sink describes a destination for the messages received by an acceptor. The
+// template renders the NATS subject, the file name, the webhook URL or the
+// Unix socket path depending on the type. Messages a required sink fails to
+// take are rejected, webhooks being called in the background a required HTTP
+// sink only fails when its queue is full.
+type Sink struct {
+	Type     string   `yaml:"Type"`
+	URL      string   `yaml:"URL"`
+	Template string   `yaml:"Template"`
+	MsgTypes []string `yaml:"MsgTypes"`
+	Required bool     `yaml:"Required"`
 }
 
 func (a *Acceptor) GetSQLStoreDriver() string {
@@ -271,18 +413,67 @@ type Session struct {
 	StartDay                string `yaml:"StartDay"`
 	EndDay                  string `yaml:"EndDay"`
 	TimeZone                string `yaml:"TimeZone"`
+	DataDictionary          string `yaml:"DataDictionary"`
 	TransportDataDictionary string `yaml:"TransportDataDictionary"`
 	AppDataDictionary       string `yaml:"AppDataDictionary"`
 	ResetOnLogon            bool   `yaml:"ResetOnLogon"`
 	ResetOnLogout           bool   `yaml:"ResetOnLogout"`
 	ResetOnDisconnect       bool   `yaml:"ResetOnDisconnect"`
 	ReconnectInterval       int    `yaml:"ReconnectInterval"`
+
+	// Throttle overrides the throttle of the acceptor serving the session.
+	Throttle *Throttle `yaml:"Throttle"`
+
+	// CancelOnDisconnect is the policy applied to the resting orders of the
+	// session when it is served by an acceptor and disconnects.
+	CancelOnDisconnect *CancelOnDisconnect `yaml:"CancelOnDisconnect"`
+}
+
+// CancelOnDisconnect tells whether the resting orders of an acceptor session
+// are canceled when it disconnects: never (off), right away (immediate) or if
+// the session did not log back on within Grace (grace).
+type CancelOnDisconnect struct {
+	Mode  string        `yaml:"Mode"`
+	Grace time.Duration `yaml:"Grace"`
+}
+
+// Validate checks the cancel-on-disconnect mode, an empty one means off.
+func (c *CancelOnDisconnect) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	switch c.Mode {
+	case "", "off", "immediate":
+	case "grace":
+		if c.Grace < time.Second {
+			return fmt.Errorf("%w: grace period must be at least a second", errors.ConfigInvalidCancelOnDisconnect)
+		}
+	default:
+		return fmt.Errorf("%w: unknown mode %s", errors.ConfigInvalidCancelOnDisconnect, c.Mode)
+	}
+
+	return nil
 }
 
 func (s *Session) GetName() string {
 	return s.Name
 }
 
+// SessionID returns the identifier quickfix registers the session under.
+func (s *Session) SessionID() quickfix.SessionID {
+	return quickfix.SessionID{
+		BeginString:      s.BeginString,
+		TargetCompID:     s.TargetCompID,
+		TargetSubID:      s.TargetSubID,
+		TargetLocationID: s.TargetLocationID,
+		SenderCompID:     s.SenderCompID,
+		SenderSubID:      s.SenderSubID,
+		SenderLocationID: s.SenderLocationID,
+		Qualifier:        s.SessionQualifier,
+	}
+}
+
 func (c Context) GetInitiator() (*Initiator, error) {
 	return GetInitiator(c.Initiator)
 }
@@ -298,6 +489,38 @@ func (c Context) GetSession(name string) (*Session, error) {
 	return GetSession(name)
 }
 
+// GetSessionFor returns the session of the context messages of the given type
+// are sent on: the one named if any, else the one routed for the type, else
+// the first one.
+func (c Context) GetSessionFor(msgType enum.MsgType, name string) (*Session, error) {
+	if len(name) == 0 {
+		name = c.Routes[string(msgType)]
+	}
+
+	if len(name) == 0 {
+		if len(c.Sessions) == 0 {
+			return nil, errors.ConfigContextNoSession
+		}
+		name = c.Sessions[0]
+	}
+
+	if utils.Search(c.Sessions, name) < 0 {
+		return nil, fmt.Errorf("%w: %s", errors.ConfigSessionNotInContext, name)
+	}
+
+	return GetSession(name)
+}
+
+// validateRoutes checks that messages are routed to sessions of the context.
+func (c Context) validateRoutes() error {
+	for msgType, name := range c.Routes {
+		if utils.Search(c.Sessions, name) < 0 {
+			return fmt.Errorf("%w: %s route to %s", errors.ConfigSessionNotInContext, msgType, name)
+		}
+	}
+	return nil
+}
+
 func (c Context) GetSessions() ([]*Session, error) {
 	sessions := make([]*Session, len(c.Sessions))
 	for i, name := range c.Sessions {
@@ -310,7 +533,7 @@ func (c Context) GetSessions() ([]*Session, error) {
 	return sessions, nil
 }
 
-func (c Context) ToQuickFixInitiatorSettings() (*quickfix.Settings, error) {
+func (c Context) ToQuickFixInitiatorSettings(names ...string) (*quickfix.Settings, error) {
 	settings := quickfix.NewSettings()
 	globalSettings := settings.GlobalSettings()
 
@@ -322,8 +545,10 @@ func (c Context) ToQuickFixInitiatorSettings() (*quickfix.Settings, error) {
 	sessions, err := c.GetSessions()
 	if err != nil {
 		return nil, err
-	} else if len(sessions) != 1 {
-		return nil, errors.ConfigContextMultipleSessions
+	}
+
+	if err = c.validateRoutes(); err != nil {
+		return nil, err
 	}
 
 	// Configure SQLStore
@@ -335,58 +560,69 @@ func (c Context) ToQuickFixInitiatorSettings() (*quickfix.Settings, error) {
 		}
 	}
 
+	for _, name := range names {
+		if utils.Search(c.Sessions, name) < 0 {
+			return nil, fmt.Errorf("%w: %s", errors.ConfigSessionNotInContext, name)
+		}
+	}
+
 	// Session settings
-	session := sessions[0]
+	for _, session := range sessions {
+		if len(names) > 0 && utils.Search(names, session.Name) < 0 {
+			continue
+		}
 
-	sessionSettings := quickfix.NewSessionSettings()
-	initiator.setQuickFixGlobalSettings(globalSettings, sessionSettings)
+		sessionSettings := quickfix.NewSessionSettings()
+		initiator.setQuickFixGlobalSettings(globalSettings, sessionSettings)
 
-	setSessionSetting(sessionSettings, qconfig.SocketConnectHost, initiator.SocketConnectHost)
-	setSessionSetting(sessionSettings, qconfig.SocketConnectPort, initiator.SocketConnectPort)
-	setSessionSetting(sessionSettings, qconfig.SocketServerName, initiator.SocketServerName)
-	setSessionSetting(sessionSettings, qconfig.HeartBtInt, session.HeartBtInt)
-	setSessionSetting(sessionSettings, qconfig.ReconnectInterval, session.ReconnectInterval)
-	setSessionSetting(sessionSettings, qconfig.BeginString, session.BeginString)
-	setSessionSetting(sessionSettings, qconfig.DefaultApplVerID, session.DefaultApplVerID)
-	setSessionSetting(sessionSettings, qconfig.SenderCompID, session.SenderCompID)
-	setSessionSetting(sessionSettings, qconfig.SenderSubID, session.SenderSubID)
-	setSessionSetting(sessionSettings, qconfig.SenderLocationID, session.SenderLocationID)
-	setSessionSetting(sessionSettings, qconfig.TargetCompID, session.TargetCompID)
-	setSessionSetting(sessionSettings, qconfig.TargetSubID, session.TargetSubID)
-	setSessionSetting(sessionSettings, qconfig.TargetLocationID, session.TargetLocationID)
-	setSessionSetting(sessionSettings, qconfig.SessionQualifier, session.SessionQualifier)
-	setSessionSetting(sessionSettings, qconfig.BeginString, session.BeginString)
-	setSessionSetting(sessionSettings, "Username", session.Username)
-	setSessionSetting(sessionSettings, "Password", session.Password)
-	setSessionSetting(sessionSettings, qconfig.StartTime, session.StartTime)
-	setSessionSetting(sessionSettings, qconfig.EndTime, session.EndTime)
-	setSessionSetting(sessionSettings, qconfig.StartDay, session.StartDay)
-	setSessionSetting(sessionSettings, qconfig.EndDay, session.EndDay)
-	setSessionSetting(sessionSettings, qconfig.TimeZone, session.TimeZone)
-	setSessionSetting(sessionSettings, qconfig.TransportDataDictionary, os.ExpandEnv(session.TransportDataDictionary))
-	setSessionSetting(sessionSettings, qconfig.AppDataDictionary, os.ExpandEnv(session.AppDataDictionary))
-	setSessionSetting(sessionSettings, qconfig.ResetOnLogon, session.ResetOnLogon)
-	setSessionSetting(sessionSettings, qconfig.ResetOnLogout, session.ResetOnLogout)
-	setSessionSetting(sessionSettings, qconfig.ResetOnDisconnect, session.ResetOnDisconnect)
-	setSessionSetting(sessionSettings, qconfig.SQLStoreDriver, initiator.SQLStoreDriver)
-	setSessionSetting(sessionSettings, qconfig.SQLStoreDataSourceName, os.ExpandEnv(initiator.SQLStoreDataSourceName))
-	setSessionSetting(sessionSettings, qconfig.RejectInvalidMessage, initiator.RejectInvalidMessage)
+		setSessionSetting(sessionSettings, qconfig.SocketConnectHost, initiator.SocketConnectHost)
+		setSessionSetting(sessionSettings, qconfig.SocketConnectPort, initiator.SocketConnectPort)
+		setSessionSetting(sessionSettings, qconfig.SocketServerName, initiator.SocketServerName)
+		setSessionSetting(sessionSettings, qconfig.HeartBtInt, session.HeartBtInt)
+		setSessionSetting(sessionSettings, qconfig.ReconnectInterval, session.ReconnectInterval)
+		setSessionSetting(sessionSettings, qconfig.BeginString, session.BeginString)
+		setSessionSetting(sessionSettings, qconfig.DefaultApplVerID, session.DefaultApplVerID)
+		setSessionSetting(sessionSettings, qconfig.SenderCompID, session.SenderCompID)
+		setSessionSetting(sessionSettings, qconfig.SenderSubID, session.SenderSubID)
+		setSessionSetting(sessionSettings, qconfig.SenderLocationID, session.SenderLocationID)
+		setSessionSetting(sessionSettings, qconfig.TargetCompID, session.TargetCompID)
+		setSessionSetting(sessionSettings, qconfig.TargetSubID, session.TargetSubID)
+		setSessionSetting(sessionSettings, qconfig.TargetLocationID, session.TargetLocationID)
+		setSessionSetting(sessionSettings, qconfig.SessionQualifier, session.SessionQualifier)
+		setSessionSetting(sessionSettings, qconfig.BeginString, session.BeginString)
+		setSessionSetting(sessionSettings, "Username", session.Username)
+		setSessionSetting(sessionSettings, "Password", session.Password)
+		setSessionSetting(sessionSettings, qconfig.StartTime, session.StartTime)
+		setSessionSetting(sessionSettings, qconfig.EndTime, session.EndTime)
+		setSessionSetting(sessionSettings, qconfig.StartDay, session.StartDay)
+		setSessionSetting(sessionSettings, qconfig.EndDay, session.EndDay)
+		setSessionSetting(sessionSettings, qconfig.TimeZone, session.TimeZone)
+		setSessionSetting(sessionSettings, qconfig.DataDictionary, os.ExpandEnv(session.DataDictionary))
+		setSessionSetting(sessionSettings, qconfig.TransportDataDictionary, os.ExpandEnv(session.TransportDataDictionary))
+		setSessionSetting(sessionSettings, qconfig.AppDataDictionary, os.ExpandEnv(session.AppDataDictionary))
+		setSessionSetting(sessionSettings, qconfig.ResetOnLogon, session.ResetOnLogon)
+		setSessionSetting(sessionSettings, qconfig.ResetOnLogout, session.ResetOnLogout)
+		setSessionSetting(sessionSettings, qconfig.ResetOnDisconnect, session.ResetOnDisconnect)
+		setSessionSetting(sessionSettings, qconfig.SQLStoreDriver, initiator.SQLStoreDriver)
+		setSessionSetting(sessionSettings, qconfig.SQLStoreDataSourceName, os.ExpandEnv(initiator.SQLStoreDataSourceName))
+		setSessionSetting(sessionSettings, qconfig.RejectInvalidMessage, initiator.RejectInvalidMessage)
 
-	if options.Timeout != time.Duration(0) {
-		sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(options.Timeout.Seconds())))
-		sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(options.Timeout.Seconds())))
-	} else if initiator.SocketTimeout != time.Duration(0) {
-		sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(initiator.SocketTimeout.Seconds())))
-		sessionSettings.Set(qconfig.LogoutTimeout, FixIntString(int(initiator.SocketTimeout.Seconds())))
-	} else {
-		sessionSettings.Set(qconfig.LogonTimeout, "5")
-		sessionSettings.Set(qconfig.LogoutTimeout, "5")
-	}
+		if options.Timeout != time.Duration(0) {
+			sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(options.Timeout.Seconds())))
+			sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(options.Timeout.Seconds())))
+		} else if initiator.SocketTimeout != time.Duration(0) {
+			sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(initiator.SocketTimeout.Seconds())))
+			sessionSettings.Set(qconfig.LogoutTimeout, FixIntString(int(initiator.SocketTimeout.Seconds())))
+		} else {
+			sessionSettings.Set(qconfig.LogonTimeout, "5")
+			sessionSettings.Set(qconfig.LogoutTimeout, "5")
+		}
 
-	_, err = settings.AddSession(sessionSettings)
+		_, err = settings.AddSession(sessionSettings)
 
-	if err != nil {
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return settings, nil
@@ -429,6 +665,41 @@ func (c Context) ToQuickFixAcceptorSettings() (*quickfix.Settings, error) {
 		return nil, err
 	}
 
+	if err = acceptor.Throttle.Validate(); err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		if err = session.Throttle.Validate(); err != nil {
+			return nil, fmt.Errorf("session %s: %w", session.Name, err)
+		}
+		if err = acceptor.sessionThrottle(session).validateQueue(session.HeartBtInt); err != nil {
+			return nil, fmt.Errorf("session %s: %w", session.Name, err)
+		}
+		if err = session.CancelOnDisconnect.Validate(); err != nil {
+			return nil, fmt.Errorf("session %s: %w", session.Name, err)
+		}
+	}
+
+	for _, names := range [][]string{c.DropCopySessions, c.PostTradeSessions} {
+		for _, name := range names {
+			if utils.Search(c.Sessions, name) < 0 {
+				return nil, fmt.Errorf("%w: %s", errors.ConfigSessionNotInContext, name)
+			}
+		}
+	}
+
+	switch acceptor.FillMode {
+	case "", "book", "auto":
+	default:
+		return nil, fmt.Errorf("%w: %s", errors.ConfigInvalidFillMode, acceptor.FillMode)
+	}
+
+	switch acceptor.StopTrigger {
+	case "", "last", "mid", "external":
+	default:
+		return nil, fmt.Errorf("%w: %s", errors.ConfigInvalidStopTrigger, acceptor.StopTrigger)
+	}
+
 	// Tune SQLStore
 	if len(acceptor.SQLStoreDriver) > 0 {
 		if acceptor.SQLStoreDriver == "sqlite3" {
@@ -438,6 +709,32 @@ func (c Context) ToQuickFixAcceptorSettings() (*quickfix.Settings, error) {
 		}
 	}
 
+	if acceptor.DynamicSessions != nil {
+		if err = acceptor.DynamicSessions.Validate(); err != nil {
+			return nil, err
+		}
+
+		template, err := GetSession(acceptor.DynamicSessions.Session)
+		if err != nil {
+			return nil, err
+		}
+		if err = template.Throttle.Validate(); err != nil {
+			return nil, fmt.Errorf("session %s: %w", template.Name, err)
+		}
+		if err = acceptor.sessionThrottle(template).validateQueue(template.HeartBtInt); err != nil {
+			return nil, fmt.Errorf("session %s: %w", template.Name, err)
+		}
+		if err = template.CancelOnDisconnect.Validate(); err != nil {
+			return nil, fmt.Errorf("session %s: %w", template.Name, err)
+		}
+
+		globalSettings.Set(qconfig.DynamicSessions, "Y")
+		globalSettings.Set(qconfig.SocketAcceptHost, acceptor.SocketAcceptHost)
+		globalSettings.Set(qconfig.SocketAcceptPort, strconv.Itoa(acceptor.SocketAcceptPort))
+		setSessionSetting(globalSettings, DynamicSenderCompIDSetting, acceptor.DynamicSessions.SenderCompID)
+		setSessionSetting(globalSettings, DynamicTargetCompIDSetting, acceptor.DynamicSessions.TargetCompID)
+	}
+
 	for _, session := range sessions {
 		sessionSettings := quickfix.NewSessionSettings()
 		acceptor.setQuickFixGlobalSettings(globalSettings, sessionSettings)
@@ -445,37 +742,14 @@ func (c Context) ToQuickFixAcceptorSettings() (*quickfix.Settings, error) {
 		sessionSettings.Set(qconfig.SocketAcceptHost, acceptor.SocketAcceptHost)
 		sessionSettings.Set(qconfig.SocketAcceptPort, strconv.Itoa(acceptor.SocketAcceptPort))
 
-		setSessionSetting(sessionSettings, qconfig.HeartBtInt, session.HeartBtInt)
-		setSessionSetting(sessionSettings, qconfig.BeginString, session.BeginString)
-		setSessionSetting(sessionSettings, qconfig.DefaultApplVerID, session.DefaultApplVerID)
-		setSessionSetting(sessionSettings, qconfig.SenderCompID, session.SenderCompID)
-		setSessionSetting(sessionSettings, qconfig.SenderSubID, session.SenderSubID)
-		setSessionSetting(sessionSettings, qconfig.TargetCompID, session.TargetCompID)
-		setSessionSetting(sessionSettings, qconfig.TargetSubID, session.TargetSubID)
-		setSessionSetting(sessionSettings, qconfig.BeginString, session.BeginString)
-		setSessionSetting(sessionSettings, qconfig.StartTime, session.StartTime)
-		setSessionSetting(sessionSettings, qconfig.EndTime, session.EndTime)
-		setSessionSetting(sessionSettings, qconfig.StartDay, session.StartDay)
-		setSessionSetting(sessionSettings, qconfig.EndDay, session.EndDay)
-		setSessionSetting(sessionSettings, qconfig.TimeZone, session.TimeZone)
-		setSessionSetting(sessionSettings, qconfig.TransportDataDictionary, os.ExpandEnv(session.TransportDataDictionary))
-		setSessionSetting(sessionSettings, qconfig.AppDataDictionary, os.ExpandEnv(session.AppDataDictionary))
-		setSessionSetting(sessionSettings, qconfig.ResetOnLogon, session.ResetOnLogon)
-		setSessionSetting(sessionSettings, qconfig.ResetOnLogout, session.ResetOnLogout)
-		setSessionSetting(sessionSettings, qconfig.ResetOnDisconnect, session.ResetOnDisconnect)
-		setSessionSetting(sessionSettings, qconfig.SQLStoreDriver, acceptor.SQLStoreDriver)
-		setSessionSetting(sessionSettings, qconfig.SQLStoreDataSourceName, os.ExpandEnv(acceptor.SQLStoreDataSourceName))
-		setSessionSetting(sessionSettings, qconfig.RejectInvalidMessage, acceptor.RejectInvalidMessage)
+		acceptor.setQuickFixSessionSettings(sessionSettings, session, true)
 
-		if options.Timeout != time.Duration(0) {
-			sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(options.Timeout.Seconds())))
-			sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(options.Timeout.Seconds())))
-		} else if acceptor.SocketTimeout != time.Duration(0) {
-			sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(acceptor.SocketTimeout.Seconds())))
-			sessionSettings.Set(qconfig.LogoutTimeout, FixIntString(int(acceptor.SocketTimeout.Seconds())))
-		} else {
-			sessionSettings.Set(qconfig.LogonTimeout, "5")
-			sessionSettings.Set(qconfig.LogoutTimeout, "5")
+		if utils.Search(c.DropCopySessions, session.Name) >= 0 {
+			sessionSettings.Set(DropCopySetting, FixBoolString(true))
+		}
+
+		if utils.Search(c.PostTradeSessions, session.Name) >= 0 {
+			sessionSettings.Set(PostTradeSetting, FixBoolString(true))
 		}
 
 		_, err = settings.AddSession(sessionSettings)
@@ -488,10 +762,107 @@ func (c Context) ToQuickFixAcceptorSettings() (*quickfix.Settings, error) {
 	return settings, nil
 }
 
+// ToQuickFixDynamicSessionSettings returns the settings dynamic sessions are
+// created with, or nil if the acceptor does not create sessions on the fly. They
+// are kept apart from the global settings so that the template session does not
+// leak into the sessions listed in the context.
+func (c Context) ToQuickFixDynamicSessionSettings() (*quickfix.SessionSettings, error) {
+	acceptor, err := GetAcceptor(c.Acceptor)
+	if err != nil {
+		return nil, err
+	}
+
+	if acceptor.DynamicSessions == nil {
+		return nil, nil
+	}
+
+	template, err := GetSession(acceptor.DynamicSessions.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionSettings := quickfix.NewSessionSettings()
+	sessionSettings.Set(qconfig.DynamicSessions, "Y")
+	sessionSettings.Set(qconfig.SocketAcceptHost, acceptor.SocketAcceptHost)
+	sessionSettings.Set(qconfig.SocketAcceptPort, strconv.Itoa(acceptor.SocketAcceptPort))
+	acceptor.setQuickFixSessionSettings(sessionSettings, template, false)
+
+	return sessionSettings, nil
+}
+
+// setQuickFixSessionSettings sets the settings of an acceptor session, CompIDs
+// are left out of the settings of dynamic sessions templates.
+func (a *Acceptor) setQuickFixSessionSettings(sessionSettings *quickfix.SessionSettings, session *Session, compIDs bool) {
+	setSessionSetting(sessionSettings, qconfig.HeartBtInt, session.HeartBtInt)
+	setSessionSetting(sessionSettings, qconfig.BeginString, session.BeginString)
+	setSessionSetting(sessionSettings, qconfig.DefaultApplVerID, session.DefaultApplVerID)
+	setSessionSetting(sessionSettings, qconfig.BeginString, session.BeginString)
+	setSessionSetting(sessionSettings, "Username", session.Username)
+	setSessionSetting(sessionSettings, "Password", session.Password)
+	setSessionSetting(sessionSettings, qconfig.StartTime, session.StartTime)
+	setSessionSetting(sessionSettings, qconfig.EndTime, session.EndTime)
+	setSessionSetting(sessionSettings, qconfig.StartDay, session.StartDay)
+	setSessionSetting(sessionSettings, qconfig.EndDay, session.EndDay)
+	setSessionSetting(sessionSettings, qconfig.TimeZone, session.TimeZone)
+	setSessionSetting(sessionSettings, qconfig.DataDictionary, os.ExpandEnv(session.DataDictionary))
+	setSessionSetting(sessionSettings, qconfig.TransportDataDictionary, os.ExpandEnv(session.TransportDataDictionary))
+	setSessionSetting(sessionSettings, qconfig.AppDataDictionary, os.ExpandEnv(session.AppDataDictionary))
+	setSessionSetting(sessionSettings, qconfig.ResetOnLogon, session.ResetOnLogon)
+	setSessionSetting(sessionSettings, qconfig.ResetOnLogout, session.ResetOnLogout)
+	setSessionSetting(sessionSettings, qconfig.ResetOnDisconnect, session.ResetOnDisconnect)
+	setSessionSetting(sessionSettings, qconfig.SQLStoreDriver, a.SQLStoreDriver)
+	setSessionSetting(sessionSettings, qconfig.SQLStoreDataSourceName, os.ExpandEnv(a.SQLStoreDataSourceName))
+	setSessionSetting(sessionSettings, qconfig.RejectInvalidMessage, a.RejectInvalidMessage)
+
+	throttle := a.sessionThrottle(session)
+	if throttle != nil && throttle.Rate > 0 {
+		sessionSettings.Set(ThrottleRateSetting, strconv.FormatFloat(throttle.Rate, 'f', -1, 64))
+		setSessionSetting(sessionSettings, ThrottleBurstSetting, throttle.Burst)
+		setSessionSetting(sessionSettings, ThrottleActionSetting, throttle.Action)
+	}
+
+	if cod := session.CancelOnDisconnect; cod != nil {
+		setSessionSetting(sessionSettings, CancelOnDisconnectSetting, cod.Mode)
+		setSessionSetting(sessionSettings, CancelOnDisconnectGraceSetting, cod.Grace)
+	}
+
+	if compIDs {
+		setSessionSetting(sessionSettings, qconfig.SenderCompID, session.SenderCompID)
+		setSessionSetting(sessionSettings, qconfig.SenderSubID, session.SenderSubID)
+		setSessionSetting(sessionSettings, qconfig.TargetCompID, session.TargetCompID)
+		setSessionSetting(sessionSettings, qconfig.TargetSubID, session.TargetSubID)
+	}
+
+	if options.Timeout != time.Duration(0) {
+		sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(options.Timeout.Seconds())))
+		sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(options.Timeout.Seconds())))
+	} else if a.SocketTimeout != time.Duration(0) {
+		sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(a.SocketTimeout.Seconds())))
+		sessionSettings.Set(qconfig.LogoutTimeout, FixIntString(int(a.SocketTimeout.Seconds())))
+	} else {
+		sessionSettings.Set(qconfig.LogonTimeout, "5")
+		sessionSettings.Set(qconfig.LogoutTimeout, "5")
+	}
+}
+
+// GetFIXDictionaries returns the transport and application dictionaries of
+// the session, FIX.4.x sessions use their DataDictionary for both.
 func (s Session) GetFIXDictionaries() (*datadictionary.DataDictionary, *datadictionary.DataDictionary, error) {
 	var err error
 	var ok bool
 
+	if len(s.DataDictionary) > 0 {
+		if _, ok = fixDict[s.DataDictionary]; !ok {
+			path := os.ExpandEnv(s.DataDictionary)
+			fixDict[s.DataDictionary], err = datadictionary.Parse(path)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		return fixDict[s.DataDictionary], fixDict[s.DataDictionary], nil
+	}
+
 	if len(s.TransportDataDictionary) > 0 {
 		if _, ok = fixDict[s.TransportDataDictionary]; !ok {
 			path := os.ExpandEnv(s.TransportDataDictionary)
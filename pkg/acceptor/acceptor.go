@@ -1,6 +1,8 @@
 package acceptor
 
 import (
+	"net"
+
 	"github.com/rs/zerolog"
 
 	"github.com/quickfixgo/quickfix"
@@ -8,7 +10,27 @@ import (
 	"sylr.dev/fix/pkg/utils"
 )
 
-func NewAcceptor(app quickfix.Application, settings *quickfix.Settings, logger *zerolog.Logger) (*quickfix.Acceptor, error) {
+// MessageStoreFactoryWrapper is implemented by applications which need to
+// access the message stores of their sessions.
+type MessageStoreFactoryWrapper interface {
+	WrapMessageStoreFactory(quickfix.MessageStoreFactory) quickfix.MessageStoreFactory
+}
+
+// RestoredStopsTrigger is implemented by applications restoring stop orders,
+// they can only be triggered once the sessions their reports go to exist.
+type RestoredStopsTrigger interface {
+	TriggerRestoredStops()
+}
+
+// ConnectionTracker is implemented by applications which need the connections
+// of their sessions, they are given once validated.
+type ConnectionTracker interface {
+	TrackConnection(net.Conn, quickfix.SessionID)
+}
+
+// NewAcceptor returns an acceptor for the sessions of the settings, it creates
+// sessions on the fly with the dynamic settings when they are not nil.
+func NewAcceptor(app quickfix.Application, settings *quickfix.Settings, dynamicSettings *quickfix.SessionSettings, logger *zerolog.Logger) (*quickfix.Acceptor, error) {
 	var msgStoreFactory quickfix.MessageStoreFactory
 
 	if settings.GlobalSettings().HasSetting("SQLStoreDriver") {
@@ -27,5 +49,68 @@ func NewAcceptor(app quickfix.Application, settings *quickfix.Settings, logger *
 		msgStoreFactory = quickfix.NewMemoryStoreFactory()
 	}
 
-	return quickfix.NewAcceptor(app, msgStoreFactory, settings, utils.NewQuickFixLogFactory(logger))
+	if wrapper, ok := app.(MessageStoreFactoryWrapper); ok {
+		msgStoreFactory = wrapper.WrapMessageStoreFactory(msgStoreFactory)
+	}
+
+	trigger, _ := app.(RestoredStopsTrigger)
+	tracker, _ := app.(ConnectionTracker)
+
+	acceptorSettings := settings
+	var listener *quickfix.SessionID
+	if dynamicSettings != nil {
+		var err error
+		if acceptorSettings, listener, err = newDynamicAcceptorSettings(settings); err != nil {
+			return nil, err
+		} else if listener != nil {
+			app = listenerApplication{Application: app, listener: *listener}
+		}
+	}
+
+	acceptor, err := quickfix.NewAcceptor(app, msgStoreFactory, acceptorSettings, utils.NewQuickFixLogFactory(logger))
+	if err != nil {
+		return nil, err
+	}
+
+	// quickfix creates dynamic sessions from its global settings, they are only
+	// replaced once the static sessions are created.
+	if dynamicSettings != nil {
+		*acceptorSettings.GlobalSettings() = *dynamicSettings
+	}
+
+	validator, err := NewCompIDValidator(settings, listener)
+	if err != nil {
+		return nil, err
+	}
+
+	if tracker != nil {
+		acceptor.SetConnectionValidator(trackingValidator{validator: validator, tracker: tracker})
+	} else if validator != nil {
+		acceptor.SetConnectionValidator(validator)
+	}
+
+	if trigger != nil {
+		trigger.TriggerRestoredStops()
+	}
+
+	return acceptor, nil
+}
+
+// trackingValidator gives the connections accepted by the validator, if any, to
+// the tracker.
+type trackingValidator struct {
+	validator *CompIDValidator
+	tracker   ConnectionTracker
+}
+
+func (v trackingValidator) Validate(conn net.Conn, sessionID quickfix.SessionID) error {
+	if v.validator != nil {
+		if err := v.validator.Validate(conn, sessionID); err != nil {
+			return err
+		}
+	}
+
+	v.tracker.TrackConnection(conn, sessionID)
+
+	return nil
 }
@@ -0,0 +1,543 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/shopspring/decimal"
+
+	"sylr.dev/fix/pkg/acceptor/refdata"
+	"sylr.dev/fix/pkg/errors"
+)
+
+type adminSession struct {
+	SessionID            string `json:"session_id"`
+	LoggedOn             bool   `json:"logged_on"`
+	Dynamic              bool   `json:"dynamic"`
+	NextSenderMsgSeqNum  int    `json:"next_sender_msg_seq_num"`
+	NextTargetMsgSeqNum  int    `json:"next_target_msg_seq_num"`
+	MessageStoreCreation string `json:"message_store_creation,omitempty"`
+}
+
+type adminOrder struct {
+	SessionID    string    `json:"session_id"`
+	OrderID      string    `json:"order_id"`
+	ClOrdID      string    `json:"cl_ord_id"`
+	Symbol       string    `json:"symbol"`
+	Side         string    `json:"side"`
+	Type         string    `json:"type"`
+	TimeInForce  string    `json:"time_in_force"`
+	Price        string    `json:"price"`
+	Quantity     string    `json:"quantity"`
+	CumQty       string    `json:"cum_qty"`
+	LeavesQty    string    `json:"leaves_qty"`
+	Status       string    `json:"status"`
+	TransactTime time.Time `json:"transact_time"`
+}
+
+type adminTradingSession struct {
+	TradingSessionID string `json:"trading_session_id"`
+	Phase            string `json:"phase"`
+	Forced           bool   `json:"forced"`
+}
+
+type adminSymbol struct {
+	Symbol           string `json:"symbol"`
+	TradingSessionID string `json:"trading_session_id,omitempty"`
+	Phase            string `json:"phase"`
+	Halted           bool   `json:"halted"`
+}
+
+type adminSeqNums struct {
+	NextSenderMsgSeqNum int `json:"next_sender_msg_seq_num"`
+	NextTargetMsgSeqNum int `json:"next_target_msg_seq_num"`
+}
+
+// messageStoreFactory keeps track of the message stores of the sessions so
+// that the admin API can read and change their sequence numbers.
+type messageStoreFactory struct {
+	quickfix.MessageStoreFactory
+	app *Acceptor
+}
+
+func (f *messageStoreFactory) Create(sessionID quickfix.SessionID) (quickfix.MessageStore, error) {
+	store, err := f.MessageStoreFactory.Create(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	locked := &lockedMessageStore{store: store}
+
+	f.app.mux.Lock()
+	f.app.stores[sessionID] = locked
+	f.app.mux.Unlock()
+
+	return locked, nil
+}
+
+// lockedMessageStore serializes the accesses to a message store, quickfix
+// uses it from the session goroutines and the admin API from its own.
+type lockedMessageStore struct {
+	store quickfix.MessageStore
+	mux   sync.Mutex
+}
+
+func (s *lockedMessageStore) NextSenderMsgSeqNum() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.NextSenderMsgSeqNum()
+}
+
+func (s *lockedMessageStore) NextTargetMsgSeqNum() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.NextTargetMsgSeqNum()
+}
+
+func (s *lockedMessageStore) IncrNextSenderMsgSeqNum() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.IncrNextSenderMsgSeqNum()
+}
+
+func (s *lockedMessageStore) IncrNextTargetMsgSeqNum() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.IncrNextTargetMsgSeqNum()
+}
+
+func (s *lockedMessageStore) SetNextSenderMsgSeqNum(next int) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.SetNextSenderMsgSeqNum(next)
+}
+
+func (s *lockedMessageStore) SetNextTargetMsgSeqNum(next int) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.SetNextTargetMsgSeqNum(next)
+}
+
+func (s *lockedMessageStore) CreationTime() time.Time {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.CreationTime()
+}
+
+func (s *lockedMessageStore) SaveMessage(seqNum int, msg []byte) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.SaveMessage(seqNum, msg)
+}
+
+func (s *lockedMessageStore) SaveMessageAndIncrNextSenderMsgSeqNum(seqNum int, msg []byte) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.SaveMessageAndIncrNextSenderMsgSeqNum(seqNum, msg)
+}
+
+func (s *lockedMessageStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.GetMessages(beginSeqNum, endSeqNum)
+}
+
+func (s *lockedMessageStore) Refresh() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.Refresh()
+}
+
+func (s *lockedMessageStore) Reset() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.Reset()
+}
+
+func (s *lockedMessageStore) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.store.Close()
+}
+
+// setSeqNums sets both sequence numbers at once, the ones not greater than
+// zero are left unchanged.
+func (s *lockedMessageStore) setSeqNums(seqNums adminSeqNums) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if seqNums.NextSenderMsgSeqNum > 0 {
+		if err := s.store.SetNextSenderMsgSeqNum(seqNums.NextSenderMsgSeqNum); err != nil {
+			return err
+		}
+	}
+
+	if seqNums.NextTargetMsgSeqNum > 0 {
+		if err := s.store.SetNextTargetMsgSeqNum(seqNums.NextTargetMsgSeqNum); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WrapMessageStoreFactory gives the admin API access to the message stores
+// created by the factory.
+func (app *Acceptor) WrapMessageStoreFactory(factory quickfix.MessageStoreFactory) quickfix.MessageStoreFactory {
+	return &messageStoreFactory{MessageStoreFactory: factory, app: app}
+}
+
+// AdminHandler serves the admin API of the acceptor:
+//
+//	GET  /admin/sessions                     list sessions
+//	GET  /admin/orders                       list live orders
+//	POST /admin/sessions/{id}/logout         log the session out, the body is the Logout Text
+//	POST /admin/sessions/{id}/reset          reset sequence numbers of a logged out session
+//	POST /admin/sessions/{id}/seqnums        set sequence numbers of a logged out session
+//	POST /admin/sessions/{id}/messages       send the FIX message of the body
+//	GET  /admin/trading-sessions             list trading session phases
+//	POST /admin/trading-sessions/{id}/phase  force the phase of the body, none resumes the schedule
+//	GET  /admin/symbols                      list symbol phases
+//	POST /admin/symbols/{symbol}/halt        halt trading on the symbol
+//	POST /admin/symbols/{symbol}/resume      resume trading on the symbol
+//	POST /admin/symbols/{symbol}/price       set the external price triggering stop orders
+func (app *Acceptor) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/sessions", app.adminListSessions)
+	mux.HandleFunc("/admin/sessions/", app.adminSessionAction)
+	mux.HandleFunc("/admin/orders", app.adminListOrders)
+	mux.HandleFunc("/admin/trading-sessions", app.adminListTradingSessions)
+	mux.HandleFunc("/admin/trading-sessions/", app.adminTradingSessionAction)
+	mux.HandleFunc("/admin/symbols", app.adminListSymbols)
+	mux.HandleFunc("/admin/symbols/", app.adminSymbolAction)
+	return mux
+}
+
+func (app *Acceptor) adminListSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	app.mux.Lock()
+	sessions := make([]adminSession, 0, len(app.sessions))
+	for id, sessionID := range app.sessions {
+		s := adminSession{
+			SessionID: id,
+			LoggedOn:  app.loggedOn[sessionID],
+		}
+		if store, ok := app.stores[sessionID]; ok {
+			s.NextSenderMsgSeqNum = store.NextSenderMsgSeqNum()
+			s.NextTargetMsgSeqNum = store.NextTargetMsgSeqNum()
+			s.MessageStoreCreation = store.CreationTime().Format(time.RFC3339)
+		}
+		_, s.Dynamic = app.sessionSettings(sessionID)
+		sessions = append(sessions, s)
+	}
+	app.mux.Unlock()
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].SessionID < sessions[j].SessionID
+	})
+
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+func (app *Acceptor) adminListOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	live := app.engine.Orders()
+	orders := make([]adminOrder, 0, len(live))
+	for _, o := range live {
+		orders = append(orders, adminOrder{
+			SessionID:    o.SessionID.String(),
+			OrderID:      o.OrderID,
+			ClOrdID:      o.ClOrdID,
+			Symbol:       o.Symbol,
+			Side:         string(o.Side),
+			Type:         string(o.Type),
+			TimeInForce:  string(o.TimeInForce),
+			Price:        o.Price.String(),
+			Quantity:     o.Quantity.String(),
+			CumQty:       o.CumQty.String(),
+			LeavesQty:    o.LeavesQty().String(),
+			Status:       string(o.Status),
+			TransactTime: o.TransactTime,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, orders)
+}
+
+func (app *Acceptor) adminSessionAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Session ids contain no slash, the action is the last path element
+	id, action, ok := adminAction(r, "/admin/sessions/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	app.mux.Lock()
+	sessionID, found := app.sessions[id]
+	store := app.stores[sessionID]
+	loggedOn := app.loggedOn[sessionID]
+	app.mux.Unlock()
+
+	if !found || store == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%w: %s", errors.AcceptorSessionNotFound, id))
+		return
+	}
+
+	// The session owns its sequence numbers while it is logged on
+	if loggedOn && (action == "reset" || action == "seqnums") {
+		writeError(w, http.StatusConflict, fmt.Errorf("%w: %s", errors.AcceptorSessionLoggedOn, id))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch action {
+	case "logout":
+		if err = app.logout(sessionID, strings.TrimSpace(string(body))); errors.Is(err, errors.AcceptorSessionNotLoggedOn) {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+	case "reset":
+		err = store.Reset()
+	case "seqnums":
+		seqNums := adminSeqNums{}
+		if err = json.Unmarshal(body, &seqNums); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = store.setSeqNums(seqNums)
+	case "messages":
+		var msg *quickfix.Message
+		if msg, err = app.parseAdminMessage(sessionID, body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = quickfix.SendToTarget(msg, sessionID)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	app.Logger.Info().Msgf("Admin %s on session %s", action, sessionID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *Acceptor) adminListTradingSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := time.Now()
+
+	app.mux.Lock()
+	sessions := make([]adminTradingSession, 0, len(app.refData.TradingSessions))
+	for _, session := range app.refData.TradingSessions {
+		_, forced := app.phaseOverrides[session.TradingSessionID]
+		sessions = append(sessions, adminTradingSession{
+			TradingSessionID: session.TradingSessionID,
+			Phase:            string(app.tradingSessionPhase(session, now)),
+			Forced:           forced,
+		})
+	}
+	app.mux.Unlock()
+
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+func (app *Acceptor) adminTradingSessionAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, action, ok := adminAction(r, "/admin/trading-sessions/")
+	if !ok || action != "phase" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var phase refdata.Phase
+	if name := strings.TrimSpace(string(body)); len(name) > 0 {
+		if phase, err = refdata.ParsePhase(name); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if err = app.setTradingSessionPhase(id, phase); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	app.Logger.Info().Msgf("Admin %s on trading session %s", action, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *Acceptor) adminListSymbols(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := time.Now()
+
+	app.mux.Lock()
+	symbols := make([]adminSymbol, 0, len(app.refData.Instruments))
+	for _, instrument := range app.refData.Instruments {
+		symbols = append(symbols, adminSymbol{
+			Symbol:           instrument.Symbol,
+			TradingSessionID: instrument.TradingSessionID,
+			Phase:            string(app.instrumentPhase(instrument, now)),
+			Halted:           app.halts[instrument.Symbol],
+		})
+	}
+	app.mux.Unlock()
+
+	writeJSON(w, http.StatusOK, symbols)
+}
+
+func (app *Acceptor) adminSymbolAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	symbol, action, ok := adminAction(r, "/admin/symbols/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch action {
+	case "halt", "resume":
+		if err := app.setHalted(symbol, action == "halt"); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+	case "price":
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		price, err := decimal.NewFromString(strings.TrimSpace(string(body)))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err = app.setExternalPrice(symbol, price); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	app.Logger.Info().Msgf("Admin %s on symbol %s", action, symbol)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminAction splits the path following prefix into an id and the action which
+// is its last element.
+func adminAction(r *http.Request, prefix string) (id, action string, ok bool) {
+	path := strings.TrimPrefix(r.URL.Path, prefix)
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return "", "", false
+	}
+	return path[:i], path[i+1:], true
+}
+
+// parseAdminMessage parses a FIX message whose fields are separated by SOH or
+// pipes. BeginString, BodyLength and CheckSum are optional, they are set when
+// the message is sent like the CompIDs and the sequence number.
+func (app *Acceptor) parseAdminMessage(sessionID quickfix.SessionID, raw []byte) (*quickfix.Message, error) {
+	raw = bytes.ReplaceAll(bytes.TrimSpace(raw), []byte("|"), []byte("\x01"))
+
+	var msgType []byte
+	fields := [][]byte{}
+	for _, f := range bytes.Split(raw, []byte("\x01")) {
+		switch {
+		case len(f) == 0:
+		case bytes.HasPrefix(f, []byte("8=")), bytes.HasPrefix(f, []byte("9=")), bytes.HasPrefix(f, []byte("10=")):
+		case bytes.HasPrefix(f, []byte("35=")):
+			msgType = f
+		default:
+			fields = append(fields, f)
+		}
+	}
+
+	if msgType == nil {
+		return nil, fmt.Errorf("%w: no MsgType", errors.AcceptorInvalidMessage)
+	}
+
+	buf := bytes.NewBufferString(fmt.Sprintf("8=%s\x019=0\x01", sessionID.BeginString))
+	buf.Write(msgType)
+	buf.WriteByte('\x01')
+	for _, f := range fields {
+		buf.Write(f)
+		buf.WriteByte('\x01')
+	}
+	buf.WriteString("10=000\x01")
+
+	transportDict, appDict := app.dictionaries(sessionID)
+
+	msg := quickfix.NewMessage()
+	if err := quickfix.ParseMessageWithDataDictionary(msg, buf, transportDict, appDict); err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.AcceptorInvalidMessage, err)
+	}
+
+	return msg, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,35 @@
+package application
+
+import (
+	"github.com/quickfixgo/enum"
+)
+
+// uncross ends the auction of the symbol, executing the crossing orders at
+// the equilibrium price, reports the trades and the market orders remainders
+// canceled and triggers the stop orders the trades reach. It must be called
+// with app.mux held.
+func (app *Acceptor) uncross(symbol string) {
+	fills, canceled := app.engine.Uncross(symbol)
+
+	if len(fills) > 0 {
+		app.Logger.Info().Msgf("Uncrossed %s: %d trades at %s", symbol, len(fills), fills[0].Price)
+	}
+
+	app.sendFills(fills)
+	app.publishMarketData(symbol, fills)
+	app.sendTradeCaptureReports(fills)
+
+	for _, fill := range fills {
+		app.persistOrder(fill.Aggressor)
+		app.persistOrder(fill.Resting)
+	}
+
+	for _, order := range canceled {
+		if err := app.sendExecutionReport(order, enum.ExecType_CANCELED, nil); err != nil {
+			app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", order.OrderID)
+		}
+		app.persistOrder(order)
+	}
+
+	app.triggerStops(symbol)
+}
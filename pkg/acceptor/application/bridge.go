@@ -0,0 +1,122 @@
+package application
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/acceptor/sink"
+	"sylr.dev/fix/pkg/errors"
+)
+
+// startBridge makes the acceptor publish orders, cancels and replaces for
+// the downstream OMS and relay its responses.
+func (app *Acceptor) startBridge(options *AcceptorOptions, orders *template.Template) error {
+	cancels, err := template.New("cancel").Parse(options.NATSCancelSubject)
+	if err != nil {
+		return err
+	}
+
+	replaces, err := template.New("replace").Parse(options.NATSReplaceSubject)
+	if err != nil {
+		return err
+	}
+
+	app.natsBridge = true
+	app.natsOrders = sink.NewNATS(app.natsConn, orders)
+	app.natsCancels = sink.NewNATS(app.natsConn, cancels)
+	app.natsReplace = sink.NewNATS(app.natsConn, replaces)
+
+	_, err = app.natsConn.Subscribe(options.NATSExecutionSubject, app.onNATSExecution)
+
+	return err
+}
+
+// forward publishes the message for the downstream OMS when the acceptor
+// runs as a bridge.
+func (app *Acceptor) forward(s sink.Sink, msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	if err := s.Send(sink.NewMessage(msg, sessionID)); err != nil {
+		return quickfix.NewMessageRejectError(err.Error(), int(tag.BusinessRejectReason), nil)
+	}
+	return nil
+}
+
+// dispatch gives the message to every sink, it fails when a required sink
+// fails and only logs the failures of the other ones.
+func (app *Acceptor) dispatch(msg *quickfix.Message, sessionID quickfix.SessionID) error {
+	m := sink.NewMessage(msg, sessionID)
+	for _, s := range app.sinks {
+		if err := s.Send(m); err != nil {
+			if sink.IsRequired(s) {
+				return err
+			}
+			app.Logger.Error().Err(err).Msgf("Unable to send message %s to sink", m.MsgType)
+		}
+	}
+
+	return nil
+}
+
+// onNATSExecution relays the messages of the downstream OMS to the FIX
+// session they are meant for.
+func (app *Acceptor) onNATSExecution(natsMsg *nats.Msg) {
+	// Without session header the session is deduced from the message header
+	var sessionID quickfix.SessionID
+	id := natsMsg.Header.Get(sink.NATSSessionIDHeader)
+	if len(id) > 0 {
+		var ok bool
+		app.mux.Lock()
+		sessionID, ok = app.sessions[id]
+		app.mux.Unlock()
+
+		if !ok {
+			app.Logger.Error().Err(errors.AcceptorBridgeUnknownSession).Msgf("Dropping message for session %s", id)
+			return
+		}
+	} else {
+		// The header is enough to know the FIX version of the message
+		header := quickfix.NewMessage()
+		if err := quickfix.ParseMessage(header, bytes.NewBuffer(natsMsg.Data)); err != nil {
+			app.Logger.Error().Err(err).Msgf("Unable to parse message received on %s", natsMsg.Subject)
+			return
+		}
+		sessionID.BeginString, _ = header.Header.GetString(tag.BeginString)
+	}
+
+	transportDict, appDict := app.dictionaries(sessionID)
+
+	msg := quickfix.NewMessage()
+	err := quickfix.ParseMessageWithDataDictionary(msg, bytes.NewBuffer(natsMsg.Data), transportDict, appDict)
+	if err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to parse message received on %s", natsMsg.Subject)
+		return
+	}
+
+	msgType, err := msg.MsgType()
+	if err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to get message type of message received on %s", natsMsg.Subject)
+		return
+	}
+
+	switch enum.MsgType(msgType) {
+	case enum.MsgType_EXECUTION_REPORT, enum.MsgType_ORDER_CANCEL_REJECT, enum.MsgType_BUSINESS_MESSAGE_REJECT:
+	default:
+		app.Logger.Error().Err(errors.AcceptorBridgeUnsupportedMessage).Msgf("Dropping message of type %s received on %s", msgType, natsMsg.Subject)
+		return
+	}
+
+	if len(id) == 0 {
+		err = quickfix.Send(msg)
+	} else {
+		err = quickfix.SendToTarget(msg, sessionID)
+	}
+
+	if err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to relay message received on %s", natsMsg.Subject)
+	}
+}
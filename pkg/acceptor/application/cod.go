@@ -0,0 +1,121 @@
+package application
+
+import (
+	"time"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/acceptor/engine"
+)
+
+// Cancel-on-disconnect modes of acceptor sessions.
+const (
+	cancelOnDisconnectOff       = "off"
+	cancelOnDisconnectImmediate = "immediate"
+	cancelOnDisconnectGrace     = "grace"
+)
+
+// cancelOnDisconnect returns the cancel-on-disconnect mode of the session and
+// its grace period.
+func (app *Acceptor) cancelOnDisconnect(sessionID quickfix.SessionID) (string, time.Duration) {
+	settings, _ := app.sessionSettings(sessionID)
+	if settings == nil || !settings.HasSetting(config.CancelOnDisconnectSetting) {
+		return cancelOnDisconnectOff, 0
+	}
+
+	mode, err := settings.Setting(config.CancelOnDisconnectSetting)
+	if err != nil {
+		return cancelOnDisconnectOff, 0
+	}
+
+	var grace time.Duration
+	if settings.HasSetting(config.CancelOnDisconnectGraceSetting) {
+		seconds, err := settings.IntSetting(config.CancelOnDisconnectGraceSetting)
+		if err != nil {
+			app.Logger.Error().Err(err).Msgf("Invalid cancel-on-disconnect grace period for session %s", sessionID)
+			return cancelOnDisconnectOff, 0
+		}
+		grace = time.Duration(seconds) * time.Second
+	}
+
+	return mode, grace
+}
+
+// onDisconnect applies the cancel-on-disconnect policy of the session which
+// just logged out. With a grace period, orders are only canceled if the
+// session has not logged back on in the meantime.
+func (app *Acceptor) onDisconnect(sessionID quickfix.SessionID) {
+	mode, grace := app.cancelOnDisconnect(sessionID)
+
+	switch mode {
+	case cancelOnDisconnectImmediate:
+		app.mux.Lock()
+		app.cancelSessionOrders(sessionID)
+		app.mux.Unlock()
+
+	case cancelOnDisconnectGrace:
+		app.mux.Lock()
+		defer app.mux.Unlock()
+
+		if timer, ok := app.codTimers[sessionID]; ok {
+			timer.Stop()
+		}
+
+		app.codTimers[sessionID] = time.AfterFunc(grace, func() {
+			app.mux.Lock()
+			defer app.mux.Unlock()
+
+			delete(app.codTimers, sessionID)
+			if !app.loggedOn[sessionID] {
+				app.cancelSessionOrders(sessionID)
+			}
+		})
+	}
+}
+
+// cancelSessionOrders cancels the resting orders of the session. The canceled
+// execution reports are held until the session logs back on. It must be
+// called with app.mux held.
+func (app *Acceptor) cancelSessionOrders(sessionID quickfix.SessionID) {
+	orders := app.engine.CancelAll(func(order *engine.Order) bool {
+		return order.SessionID == sessionID
+	})
+	if len(orders) == 0 {
+		return
+	}
+
+	app.Logger.Info().Msgf("Canceling %d orders of disconnected session %s", len(orders), sessionID)
+
+	symbols := make(map[string]bool)
+	for _, order := range orders {
+		app.pendingReports[sessionID] = append(app.pendingReports[sessionID], newExecutionReport(order, enum.ExecType_CANCELED, nil))
+		app.persistOrder(order)
+		symbols[order.Symbol] = true
+	}
+
+	for symbol := range symbols {
+		app.publishMarketData(symbol, nil)
+	}
+}
+
+// onReconnect stops the pending cancel-on-disconnect of the session and sends
+// the execution reports of the orders canceled while it was away. They are
+// delivered once the logon completes.
+func (app *Acceptor) onReconnect(sessionID quickfix.SessionID) {
+	app.mux.Lock()
+	if timer, ok := app.codTimers[sessionID]; ok {
+		timer.Stop()
+		delete(app.codTimers, sessionID)
+	}
+	reports := app.pendingReports[sessionID]
+	delete(app.pendingReports, sessionID)
+	app.mux.Unlock()
+
+	for _, report := range reports {
+		if err := quickfix.SendToTarget(report, sessionID); err != nil {
+			app.Logger.Error().Err(err).Msgf("Unable to send pending execution report to %s", sessionID)
+		}
+	}
+}
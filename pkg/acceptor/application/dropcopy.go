@@ -0,0 +1,99 @@
+package application
+
+import (
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/utils"
+)
+
+// Messages drop-copy sessions are not allowed to send.
+var orderEntryMsgTypes = []enum.MsgType{
+	enum.MsgType_ORDER_SINGLE,
+	enum.MsgType_ORDER_CANCEL_REQUEST,
+	enum.MsgType_ORDER_CANCEL_REPLACE_REQUEST,
+	enum.MsgType_ORDER_LIST,
+	enum.MsgType_ORDER_MASS_CANCEL_REQUEST,
+	enum.MsgType_NEW_ORDER_MULTILEG,
+	enum.MsgType_MULTILEG_ORDER_CANCEL_REPLACE,
+	enum.MsgType_NEW_ORDER_CROSS,
+}
+
+// registerDropCopySession remembers the session if it is a drop-copy one.
+func (app *Acceptor) registerDropCopySession(sessionID quickfix.SessionID) {
+	if !app.boolSetting(sessionID, config.DropCopySetting) {
+		return
+	}
+
+	app.dropCopyMux.Lock()
+	app.dropCopySessions[sessionID] = false
+	app.dropCopyMux.Unlock()
+
+	app.Logger.Info().Msgf("Drop-copy session: %s", sessionID)
+}
+
+// setDropCopyLoggedOn records the logon state of drop-copy sessions, copies
+// are only sent to logged on sessions.
+func (app *Acceptor) setDropCopyLoggedOn(sessionID quickfix.SessionID, loggedOn bool) {
+	app.dropCopyMux.Lock()
+	defer app.dropCopyMux.Unlock()
+
+	if _, ok := app.dropCopySessions[sessionID]; ok {
+		app.dropCopySessions[sessionID] = loggedOn
+	}
+}
+
+func (app *Acceptor) isDropCopySession(sessionID quickfix.SessionID) bool {
+	app.dropCopyMux.RLock()
+	defer app.dropCopyMux.RUnlock()
+
+	_, ok := app.dropCopySessions[sessionID]
+	return ok
+}
+
+// dropCopy mirrors an execution report sent on a trading session to the
+// drop-copy sessions. The CompIDs of the trading session are kept in the
+// OnBehalfOf and DeliverTo fields. It is called from ToApp so it must not
+// take app.mux which is held while execution reports are sent.
+func (app *Acceptor) dropCopy(report *quickfix.Message, sessionID quickfix.SessionID) {
+	if report.Header.Has(tag.PossDupFlag) {
+		return
+	}
+
+	app.dropCopyMux.RLock()
+	targets := make([]quickfix.SessionID, 0, len(app.dropCopySessions))
+	for target, loggedOn := range app.dropCopySessions {
+		if loggedOn && target != sessionID {
+			targets = append(targets, target)
+		}
+	}
+	app.dropCopyMux.RUnlock()
+
+	for _, target := range targets {
+		message := newMessage(target, enum.MsgType_EXECUTION_REPORT)
+
+		utils.QuickFixMessagePartSetString(&message.Header, sessionID.SenderCompID, field.NewOnBehalfOfCompID)
+		utils.QuickFixMessagePartSetString(&message.Header, sessionID.SenderSubID, field.NewOnBehalfOfSubID)
+		utils.QuickFixMessagePartSetString(&message.Header, sessionID.TargetCompID, field.NewDeliverToCompID)
+		utils.QuickFixMessagePartSetString(&message.Header, sessionID.TargetSubID, field.NewDeliverToSubID)
+
+		report.Body.CopyInto(&message.Body.FieldMap)
+		setExecutionReportVersion(&message.Body, target.BeginString)
+
+		if err := quickfix.SendToTarget(message, target); err != nil {
+			app.Logger.Error().Err(err).Msgf("Unable to send drop copy to %s", target)
+		}
+	}
+}
+
+func isOrderEntry(msgType string) bool {
+	for _, t := range orderEntryMsgTypes {
+		if string(t) == msgType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package application
+
+import (
+	"time"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	qconfig "github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/acceptor/engine"
+)
+
+// finalOrderRetention is how long orders in a final state can still be looked
+// up, e.g. to reject the cancels of filled orders as such.
+const finalOrderRetention = time.Hour
+
+// expireOrders expires the orders whose expire time is past every second and
+// evicts the old ones every minute until the acceptor is closed.
+func (app *Acceptor) expireOrders() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	evictions := time.NewTicker(time.Minute)
+	defer evictions.Stop()
+
+	for {
+		select {
+		case <-app.done:
+			return
+		case now := <-ticker.C:
+			app.expire(now)
+		case now := <-evictions.C:
+			app.evict(now)
+		}
+	}
+}
+
+// evict forgets the orders final for longer than finalOrderRetention and the
+// trades of the previous days, trade capture report requests only replay the
+// trades of the day.
+func (app *Acceptor) evict(now time.Time) {
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+
+	orders := app.engine.EvictOrders(now, finalOrderRetention)
+	trades := app.engine.EvictTrades(today)
+
+	if orders > 0 || trades > 0 {
+		app.Logger.Debug().Msgf("Evicted %d final orders and %d trades", orders, trades)
+	}
+}
+
+func (app *Acceptor) expire(now time.Time) {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	symbols := make(map[string]bool)
+	for _, order := range app.engine.Expire(now) {
+		if err := app.sendExecutionReport(order, enum.ExecType_EXPIRED, nil); err != nil {
+			app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", order.OrderID)
+		}
+		app.persistOrder(order)
+		symbols[order.Symbol] = true
+	}
+
+	for symbol := range symbols {
+		app.publishMarketData(symbol, nil)
+	}
+}
+
+// orderExpireTime returns when the order expires: at the end of its session
+// for DAY orders and at its ExpireTime, or the end of its ExpireDate, for GTD
+// ones. Other orders do not expire.
+func (app *Acceptor) orderExpireTime(msg *quickfix.Message, order *engine.Order) (time.Time, quickfix.MessageRejectError) {
+	switch order.TimeInForce {
+	case enum.TimeInForce_DAY:
+		return app.sessionEndTime(order.SessionID, order.TransactTime), nil
+
+	case enum.TimeInForce_GOOD_TILL_DATE:
+		if msg.Body.Has(tag.ExpireTime) {
+			return msg.Body.GetTime(tag.ExpireTime)
+		}
+
+		if msg.Body.Has(tag.ExpireDate) {
+			expireDate, ferr := msg.Body.GetString(tag.ExpireDate)
+			if ferr != nil {
+				return time.Time{}, ferr
+			}
+
+			day, err := time.ParseInLocation("20060102", expireDate, app.sessionLocation(order.SessionID))
+			if err != nil {
+				return time.Time{}, quickfix.IncorrectDataFormatForValue(tag.ExpireDate)
+			}
+
+			return day.AddDate(0, 0, 1), nil
+		}
+
+		return time.Time{}, quickfix.ConditionallyRequiredFieldMissing(tag.ExpireTime)
+	}
+
+	return time.Time{}, nil
+}
+
+// sessionEndTime returns the first end of the session after the given time,
+// or the zero time if the session has no EndTime.
+func (app *Acceptor) sessionEndTime(sessionID quickfix.SessionID, after time.Time) time.Time {
+	settings, _ := app.sessionSettings(sessionID)
+	if settings == nil || !settings.HasSetting(qconfig.EndTime) {
+		return time.Time{}
+	}
+
+	raw, err := settings.Setting(qconfig.EndTime)
+	if err != nil {
+		return time.Time{}
+	}
+
+	end, err := time.Parse("15:04:05", raw)
+	if err != nil {
+		app.Logger.Error().Err(err).Msgf("Invalid EndTime for session %s", sessionID)
+		return time.Time{}
+	}
+
+	t := after.In(app.sessionLocation(sessionID))
+	expire := time.Date(t.Year(), t.Month(), t.Day(), end.Hour(), end.Minute(), end.Second(), 0, t.Location())
+	if !expire.After(after) {
+		expire = expire.AddDate(0, 0, 1)
+	}
+
+	return expire
+}
+
+// sessionLocation returns the TimeZone of the session, UTC by default like
+// quickfix.
+func (app *Acceptor) sessionLocation(sessionID quickfix.SessionID) *time.Location {
+	settings, _ := app.sessionSettings(sessionID)
+	if settings == nil || !settings.HasSetting(qconfig.TimeZone) {
+		return time.UTC
+	}
+
+	tz, err := settings.Setting(qconfig.TimeZone)
+	if err != nil {
+		return time.UTC
+	} else if tz == "Local" {
+		return time.Local
+	}
+
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// persistOrder records the state of GTC orders in the store, final orders are
+// removed from it.
+func (app *Acceptor) persistOrder(order engine.Order) {
+	if app.gtcStore == nil || order.TimeInForce != enum.TimeInForce_GOOD_TILL_CANCEL {
+		return
+	}
+
+	var err error
+	if order.IsFinal() {
+		err = app.gtcStore.Delete(order.OrderID)
+	} else {
+		err = app.gtcStore.Save(order)
+	}
+
+	if err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to persist GTC order %s", order.OrderID)
+	}
+}
@@ -0,0 +1,96 @@
+package application
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+	"golang.org/x/crypto/bcrypt"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+var metricLogonFailures = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "fix",
+		Subsystem: "acceptor",
+		Name:      "logon_failures_total",
+		Help:      "Number of logons rejected because of invalid credentials",
+	},
+	[]string{"session"},
+)
+
+func init() {
+	prometheus.MustRegister(metricLogonFailures)
+}
+
+// checkLogon rejects the logon if its credentials do not match the Username
+// and Password settings of the session, or of the template of dynamic
+// sessions. Sessions without credentials accept any logon. Passwords can be
+// given as bcrypt hashes.
+func (app *Acceptor) checkLogon(logon *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	session, _ := app.sessionSettings(sessionID)
+	if session == nil || (!session.HasSetting("Username") && !session.HasSetting("Password")) {
+		return nil
+	}
+
+	expectedUsername, _ := session.Setting("Username")
+	expectedPassword, _ := session.Setting("Password")
+
+	username := logonCredential(logon, tag.Username)
+	password := logonCredential(logon, tag.Password)
+
+	valid := subtle.ConstantTimeCompare([]byte(username), []byte(expectedUsername)) == 1
+	if isBcryptHash(expectedPassword) {
+		valid = bcrypt.CompareHashAndPassword([]byte(expectedPassword), []byte(password)) == nil && valid
+	} else {
+		valid = subtle.ConstantTimeCompare([]byte(password), []byte(expectedPassword)) == 1 && valid
+	}
+
+	if valid {
+		return nil
+	}
+
+	metricLogonFailures.WithLabelValues(sessionID.String()).Inc()
+
+	app.mux.Lock()
+	app.logonFailures[sessionID]++
+	attempts := app.logonFailures[sessionID]
+	app.mux.Unlock()
+
+	app.Logger.Warn().Err(errors.AcceptorInvalidCredentials).Msgf("Logon of %s as %q rejected (%d failed attempts)", sessionID, username, attempts)
+
+	return quickfix.RejectLogon{Text: errors.AcceptorInvalidCredentials.Error()}
+}
+
+// setLogoutSessionStatus tells the counterparty why its logon was rejected,
+// SessionStatus only exists in FIXT.1.1.
+func (app *Acceptor) setLogoutSessionStatus(logout *quickfix.Message, sessionID quickfix.SessionID) {
+	if sessionID.BeginString != quickfix.BeginStringFIXT11 {
+		return
+	}
+
+	text, err := logout.Body.GetString(tag.Text)
+	if err != nil || text != errors.AcceptorInvalidCredentials.Error() {
+		return
+	}
+
+	logout.Body.Set(field.NewSessionStatus(enum.SessionStatus_INVALID_USERNAME_OR_PASSWORD))
+}
+
+// The initiators of this repository put the credentials in the header.
+func logonCredential(logon *quickfix.Message, t quickfix.Tag) string {
+	if value, err := logon.Body.GetString(t); err == nil {
+		return value
+	}
+	value, _ := logon.Header.GetString(t)
+	return value
+}
+
+func isBcryptHash(s string) bool {
+	return strings.HasPrefix(s, "$2a$") || strings.HasPrefix(s, "$2b$") || strings.HasPrefix(s, "$2y$")
+}
@@ -0,0 +1,115 @@
+package application
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	qconfig "github.com/quickfixgo/quickfix/config"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// defaultLogoutTimeout is the quickfix default of the LogoutTimeout setting.
+const defaultLogoutTimeout = 2 * time.Second
+
+// TrackConnection records the connection of the session once it is validated
+// so that logouts initiated by the acceptor can drop it.
+func (app *Acceptor) TrackConnection(conn net.Conn, sessionID quickfix.SessionID) {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	app.conns[sessionID] = conn
+}
+
+// logout initiates the logout of a logged on session. quickfix does not let
+// applications drive its session state machine, so the acceptor plays the
+// part of its logout state: it waits for the Logout response or the logout
+// timeout and then drops the connection, the session disconnecting as it
+// would after a logout it initiated itself.
+func (app *Acceptor) logout(sessionID quickfix.SessionID, text string) error {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	if !app.loggedOn[sessionID] {
+		return fmt.Errorf("%w: %s", errors.AcceptorSessionNotLoggedOn, sessionID)
+	}
+
+	if _, ok := app.logoutTimers[sessionID]; ok {
+		return nil
+	}
+
+	message := newMessage(sessionID, enum.MsgType_LOGOUT)
+	if len(text) > 0 {
+		message.Body.Set(field.NewText(text))
+	}
+
+	if err := quickfix.SendToTarget(message, sessionID); err != nil {
+		return err
+	}
+
+	app.logoutTimers[sessionID] = time.AfterFunc(app.logoutTimeout(sessionID), func() {
+		app.Logger.Warn().Msgf("Timed out waiting for logout response from %s", sessionID)
+		app.dropConnection(sessionID)
+	})
+
+	return nil
+}
+
+// onLogoutResponse drops the connection of a session answering a logout the
+// acceptor initiated, quickfix would otherwise take the response for a logout
+// request and answer it.
+func (app *Acceptor) onLogoutResponse(sessionID quickfix.SessionID) {
+	app.mux.Lock()
+	_, ok := app.logoutTimers[sessionID]
+	app.mux.Unlock()
+
+	if ok {
+		app.dropConnection(sessionID)
+	}
+}
+
+func (app *Acceptor) dropConnection(sessionID quickfix.SessionID) {
+	app.mux.Lock()
+	conn, ok := app.conns[sessionID]
+	app.mux.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if err := conn.Close(); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to close connection of session %s", sessionID)
+	}
+}
+
+// forgetConnection is called when the session disconnects. app.mux must be
+// held.
+func (app *Acceptor) forgetConnection(sessionID quickfix.SessionID) {
+	if timer, ok := app.logoutTimers[sessionID]; ok {
+		timer.Stop()
+		delete(app.logoutTimers, sessionID)
+	}
+
+	delete(app.conns, sessionID)
+}
+
+func (app *Acceptor) logoutTimeout(sessionID quickfix.SessionID) time.Duration {
+	settings, _ := app.sessionSettings(sessionID)
+	if settings == nil || !settings.HasSetting(qconfig.LogoutTimeout) {
+		return defaultLogoutTimeout
+	}
+
+	if timeout, err := settings.DurationSetting(qconfig.LogoutTimeout); err == nil && timeout > 0 {
+		return timeout
+	}
+
+	if seconds, err := settings.IntSetting(qconfig.LogoutTimeout); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return defaultLogoutTimeout
+}
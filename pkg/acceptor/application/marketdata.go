@@ -0,0 +1,382 @@
+package application
+
+import (
+	"time"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/acceptor/engine"
+	"sylr.dev/fix/pkg/errors"
+)
+
+// mdSubscription is a market data subscription of a session. It keeps the
+// levels and the indicative uncross last published for each symbol so that
+// incremental refreshes only carry what changed.
+type mdSubscription struct {
+	mdReqID    string
+	sessionID  quickfix.SessionID
+	symbols    []string
+	entryTypes map[enum.MDEntryType]bool
+	depth      int
+	updateType enum.MDUpdateType
+	published  map[string]*mdLevels
+}
+
+type mdLevels struct {
+	bids       []engine.Level
+	asks       []engine.Level
+	indicative *engine.Level
+}
+
+func (sub *mdSubscription) hasSymbol(symbol string) bool {
+	for _, s := range sub.symbols {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Acceptor) onMarketDataRequest(msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	mdReqID, ferr := msg.Body.GetString(tag.MDReqID)
+	if ferr != nil {
+		return ferr
+	}
+
+	subType := field.SubscriptionRequestTypeField{}
+	if ferr = msg.Body.GetField(tag.SubscriptionRequestType, &subType); ferr != nil {
+		return ferr
+	}
+
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	if subType.Value() == enum.SubscriptionRequestType_DISABLE_PREVIOUS_SNAPSHOT_PLUS_UPDATE_REQUEST {
+		// FIX has no reason for unknown requests, Text tells it apart
+		if _, ok := app.mdSubscriptions[sessionID][mdReqID]; !ok {
+			app.sendMarketDataRequestReject(sessionID, mdReqID, enum.MDReqRejReason_UNKNOWN_SYMBOL, errors.AcceptorMarketDataRequestNotFound)
+			return nil
+		}
+
+		delete(app.mdSubscriptions[sessionID], mdReqID)
+		return nil
+	}
+
+	sub := &mdSubscription{
+		mdReqID:    mdReqID,
+		sessionID:  sessionID,
+		entryTypes: make(map[enum.MDEntryType]bool),
+		updateType: enum.MDUpdateType_INCREMENTAL_REFRESH,
+		published:  make(map[string]*mdLevels),
+	}
+
+	if msg.Body.Has(tag.MarketDepth) {
+		if sub.depth, ferr = msg.Body.GetInt(tag.MarketDepth); ferr != nil {
+			return ferr
+		}
+	}
+
+	if msg.Body.Has(tag.MDUpdateType) {
+		updateType := field.MDUpdateTypeField{}
+		if ferr = msg.Body.GetField(tag.MDUpdateType, &updateType); ferr != nil {
+			return ferr
+		}
+		sub.updateType = updateType.Value()
+	}
+
+	entryTypes := quickfix.NewRepeatingGroup(
+		tag.NoMDEntryTypes,
+		quickfix.GroupTemplate{
+			quickfix.GroupElement(tag.MDEntryType),
+		},
+	)
+	if ferr = msg.Body.GetGroup(entryTypes); ferr != nil {
+		return ferr
+	}
+
+	for i := 0; i < entryTypes.Len(); i++ {
+		entryType, ferr := entryTypes.Get(i).GetString(tag.MDEntryType)
+		if ferr != nil {
+			return ferr
+		}
+		sub.entryTypes[enum.MDEntryType(entryType)] = true
+	}
+
+	// Serve everything we have if no entry type is requested
+	if len(sub.entryTypes) == 0 {
+		sub.entryTypes[enum.MDEntryType_BID] = true
+		sub.entryTypes[enum.MDEntryType_OFFER] = true
+		sub.entryTypes[enum.MDEntryType_TRADE] = true
+		sub.entryTypes[enum.MDEntryType_AUCTION_CLEARING_PRICE] = true
+	}
+
+	relatedSym := quickfix.NewRepeatingGroup(
+		tag.NoRelatedSym,
+		quickfix.GroupTemplate{
+			quickfix.GroupElement(tag.Symbol),
+		},
+	)
+	if ferr = msg.Body.GetGroup(relatedSym); ferr != nil {
+		return ferr
+	}
+
+	for i := 0; i < relatedSym.Len(); i++ {
+		symbol, ferr := relatedSym.Get(i).GetString(tag.Symbol)
+		if ferr != nil {
+			return ferr
+		}
+		sub.symbols = append(sub.symbols, symbol)
+	}
+
+	if len(sub.symbols) == 0 {
+		app.sendMarketDataRequestReject(sessionID, mdReqID, enum.MDReqRejReason_UNKNOWN_SYMBOL, errors.AcceptorMarketDataNoSymbol)
+		return nil
+	}
+
+	if subType.Value() == enum.SubscriptionRequestType_SNAPSHOT_PLUS_UPDATES {
+		if _, ok := app.mdSubscriptions[sessionID][mdReqID]; ok {
+			app.sendMarketDataRequestReject(sessionID, mdReqID, enum.MDReqRejReason_DUPLICATE_MDREQID, errors.AcceptorMarketDataRequestDuplicate)
+			return nil
+		}
+
+		if _, ok := app.mdSubscriptions[sessionID]; !ok {
+			app.mdSubscriptions[sessionID] = make(map[string]*mdSubscription)
+		}
+		app.mdSubscriptions[sessionID][mdReqID] = sub
+	}
+
+	for _, symbol := range sub.symbols {
+		app.sendMarketDataSnapshot(sub, symbol)
+	}
+
+	return nil
+}
+
+// publishMarketData sends the changes of the symbol book and the given trades
+// to every subscription covering the symbol. It must be called with app.mux
+// held.
+func (app *Acceptor) publishMarketData(symbol string, fills []engine.Fill) {
+	for _, subs := range app.mdSubscriptions {
+		for _, sub := range subs {
+			if !sub.hasSymbol(symbol) {
+				continue
+			}
+
+			if sub.updateType == enum.MDUpdateType_FULL_REFRESH {
+				app.sendMarketDataSnapshot(sub, symbol)
+			} else {
+				app.sendMarketDataIncrement(sub, symbol, fills)
+			}
+		}
+	}
+}
+
+// sendMarketDataSnapshot sends the current book levels and the last trade of
+// the symbol.
+func (app *Acceptor) sendMarketDataSnapshot(sub *mdSubscription, symbol string) {
+	message := newMessage(sub.sessionID, enum.MsgType_MARKET_DATA_SNAPSHOT_FULL_REFRESH)
+
+	message.Body.Set(field.NewMDReqID(sub.mdReqID))
+	message.Body.Set(field.NewSymbol(symbol))
+
+	entries := quickfix.NewRepeatingGroup(
+		tag.NoMDEntries,
+		quickfix.GroupTemplate{
+			quickfix.GroupElement(tag.MDEntryType),
+			quickfix.GroupElement(tag.MDEntryPx),
+			quickfix.GroupElement(tag.MDEntrySize),
+			quickfix.GroupElement(tag.TradeID),
+		},
+	)
+
+	bids, asks := app.engine.Depth(symbol, sub.depth)
+	indicative := app.indicative(symbol)
+	sub.published[symbol] = &mdLevels{bids: bids, asks: asks, indicative: indicative}
+
+	addLevels := func(entryType enum.MDEntryType, levels []engine.Level) {
+		if !sub.entryTypes[entryType] {
+			return
+		}
+		for _, level := range levels {
+			entry := entries.Add()
+			entry.Set(field.NewMDEntryType(entryType))
+			entry.Set(field.NewMDEntryPx(level.Price, 2))
+			entry.Set(field.NewMDEntrySize(level.Size, 2))
+		}
+	}
+
+	addLevels(enum.MDEntryType_BID, bids)
+	addLevels(enum.MDEntryType_OFFER, asks)
+
+	if trade := app.engine.LastTrade(symbol); trade != nil && sub.entryTypes[enum.MDEntryType_TRADE] {
+		entry := entries.Add()
+		entry.Set(field.NewMDEntryType(enum.MDEntryType_TRADE))
+		entry.Set(field.NewMDEntryPx(trade.Price, 2))
+		entry.Set(field.NewMDEntrySize(trade.Quantity, 2))
+		entry.Set(field.NewTradeID(trade.TradeID))
+	}
+
+	if indicative != nil && sub.entryTypes[enum.MDEntryType_AUCTION_CLEARING_PRICE] {
+		entry := entries.Add()
+		entry.Set(field.NewMDEntryType(enum.MDEntryType_AUCTION_CLEARING_PRICE))
+		entry.Set(field.NewMDEntryPx(indicative.Price, 2))
+		entry.Set(field.NewMDEntrySize(indicative.Size, 2))
+	}
+
+	message.Body.Set(field.NewLastUpdateTime(time.Now()))
+	setMarketDataVersion(&message.Body, entries, sub.sessionID.BeginString)
+	message.Body.SetGroup(entries)
+
+	if err := quickfix.SendToTarget(message, sub.sessionID); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send market data snapshot for %s", sub.mdReqID)
+	}
+}
+
+// sendMarketDataIncrement sends the levels of the symbol which changed since
+// the last publication along with the given trades. Nothing is sent if there
+// is no change.
+func (app *Acceptor) sendMarketDataIncrement(sub *mdSubscription, symbol string, fills []engine.Fill) {
+	message := newMessage(sub.sessionID, enum.MsgType_MARKET_DATA_INCREMENTAL_REFRESH)
+
+	message.Body.Set(field.NewMDReqID(sub.mdReqID))
+
+	entries := quickfix.NewRepeatingGroup(
+		tag.NoMDEntries,
+		quickfix.GroupTemplate{
+			quickfix.GroupElement(tag.MDUpdateAction),
+			quickfix.GroupElement(tag.MDEntryType),
+			quickfix.GroupElement(tag.MDEntryPx),
+			quickfix.GroupElement(tag.MDEntrySize),
+			quickfix.GroupElement(tag.TradeID),
+			quickfix.GroupElement(tag.Symbol),
+		},
+	)
+
+	previous, ok := sub.published[symbol]
+	if !ok {
+		previous = &mdLevels{}
+	}
+
+	bids, asks := app.engine.Depth(symbol, sub.depth)
+	indicative := app.indicative(symbol)
+	sub.published[symbol] = &mdLevels{bids: bids, asks: asks, indicative: indicative}
+
+	addDiff := func(entryType enum.MDEntryType, before, after []engine.Level) {
+		if !sub.entryTypes[entryType] {
+			return
+		}
+
+		for _, level := range after {
+			action := enum.MDUpdateAction_NEW
+			if old, found := findLevel(before, level); found {
+				if old.Size.Equal(level.Size) {
+					continue
+				}
+				action = enum.MDUpdateAction_CHANGE
+			}
+
+			entry := entries.Add()
+			entry.Set(field.NewMDUpdateAction(action))
+			entry.Set(field.NewMDEntryType(entryType))
+			entry.Set(field.NewMDEntryPx(level.Price, 2))
+			entry.Set(field.NewMDEntrySize(level.Size, 2))
+			entry.Set(field.NewSymbol(symbol))
+		}
+
+		for _, level := range before {
+			if _, found := findLevel(after, level); found {
+				continue
+			}
+
+			entry := entries.Add()
+			entry.Set(field.NewMDUpdateAction(enum.MDUpdateAction_DELETE))
+			entry.Set(field.NewMDEntryType(entryType))
+			entry.Set(field.NewMDEntryPx(level.Price, 2))
+			entry.Set(field.NewSymbol(symbol))
+		}
+	}
+
+	addDiff(enum.MDEntryType_BID, previous.bids, bids)
+	addDiff(enum.MDEntryType_OFFER, previous.asks, asks)
+
+	if sub.entryTypes[enum.MDEntryType_TRADE] {
+		for _, fill := range fills {
+			entry := entries.Add()
+			entry.Set(field.NewMDUpdateAction(enum.MDUpdateAction_NEW))
+			entry.Set(field.NewMDEntryType(enum.MDEntryType_TRADE))
+			entry.Set(field.NewMDEntryPx(fill.Price, 2))
+			entry.Set(field.NewMDEntrySize(fill.Quantity, 2))
+			entry.Set(field.NewTradeID(fill.TradeID))
+			entry.Set(field.NewSymbol(symbol))
+		}
+	}
+
+	if sub.entryTypes[enum.MDEntryType_AUCTION_CLEARING_PRICE] {
+		before := previous.indicative
+		switch {
+		case indicative != nil && (before == nil || !before.Price.Equal(indicative.Price) || !before.Size.Equal(indicative.Size)):
+			action := enum.MDUpdateAction_NEW
+			if before != nil {
+				action = enum.MDUpdateAction_CHANGE
+			}
+			entry := entries.Add()
+			entry.Set(field.NewMDUpdateAction(action))
+			entry.Set(field.NewMDEntryType(enum.MDEntryType_AUCTION_CLEARING_PRICE))
+			entry.Set(field.NewMDEntryPx(indicative.Price, 2))
+			entry.Set(field.NewMDEntrySize(indicative.Size, 2))
+			entry.Set(field.NewSymbol(symbol))
+		case indicative == nil && before != nil:
+			entry := entries.Add()
+			entry.Set(field.NewMDUpdateAction(enum.MDUpdateAction_DELETE))
+			entry.Set(field.NewMDEntryType(enum.MDEntryType_AUCTION_CLEARING_PRICE))
+			entry.Set(field.NewMDEntryPx(before.Price, 2))
+			entry.Set(field.NewSymbol(symbol))
+		}
+	}
+
+	if entries.Len() == 0 {
+		return
+	}
+
+	setMarketDataVersion(&message.Body, entries, sub.sessionID.BeginString)
+	message.Body.SetGroup(entries)
+
+	if err := quickfix.SendToTarget(message, sub.sessionID); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send market data incremental refresh for %s", sub.mdReqID)
+	}
+}
+
+func (app *Acceptor) sendMarketDataRequestReject(sessionID quickfix.SessionID, mdReqID string, reason enum.MDReqRejReason, cause error) {
+	message := newMessage(sessionID, enum.MsgType_MARKET_DATA_REQUEST_REJECT)
+
+	message.Body.Set(field.NewMDReqID(mdReqID))
+	message.Body.Set(field.NewMDReqRejReason(reason))
+	message.Body.Set(field.NewText(cause.Error()))
+
+	if err := quickfix.SendToTarget(message, sessionID); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send market data request reject for %s", mdReqID)
+	}
+}
+
+// indicative returns the price and quantity the symbol would uncross at or nil
+// if it is not in an auction or its book does not cross.
+func (app *Acceptor) indicative(symbol string) *engine.Level {
+	level, ok := app.engine.Indicative(symbol)
+	if !ok {
+		return nil
+	}
+	return &level
+}
+
+// findLevel returns the level of levels having the same price as level.
+func findLevel(levels []engine.Level, level engine.Level) (engine.Level, bool) {
+	for _, l := range levels {
+		if l.Price.Equal(level.Price) {
+			return l, true
+		}
+	}
+	return engine.Level{}, false
+}
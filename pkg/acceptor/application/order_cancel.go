@@ -0,0 +1,208 @@
+package application
+
+import (
+	"time"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/acceptor/engine"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/utils"
+)
+
+func (app *Acceptor) onOrderCancelRequest(msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	clOrdID, ferr := msg.Body.GetString(tag.ClOrdID)
+	if ferr != nil {
+		return ferr
+	}
+
+	// The downstream OMS owns the order lifecycle
+	if app.natsBridge {
+		return app.forward(app.natsCancels, msg, sessionID)
+	}
+
+	if err := app.dispatch(msg, sessionID); err != nil {
+		return quickfix.NewMessageRejectError(err.Error(), int(tag.BusinessRejectReason), nil)
+	}
+
+	origClOrdID := utils.MustNot(msg.Body.GetString(tag.OrigClOrdID))
+	orderID := utils.MustNot(msg.Body.GetString(tag.OrderID))
+	responseTo := enum.CxlRejResponseTo_ORDER_CANCEL_REQUEST
+
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	order, err := app.engine.Lookup(sessionID, orderID, origClOrdID)
+	if err != nil {
+		app.sendOrderCancelReject(sessionID, nil, clOrdID, origClOrdID, responseTo, enum.CxlRejReason_UNKNOWN_ORDER, err.Error())
+		return nil
+	}
+
+	if order.IsFinal() {
+		app.sendOrderCancelReject(sessionID, order, clOrdID, origClOrdID, responseTo, enum.CxlRejReason_TOO_LATE_TO_CANCEL, errors.AcceptorOrderFinal.Error())
+		return nil
+	}
+
+	pending := *order
+	pending.OrigClOrdID = order.ClOrdID
+	pending.ClOrdID = clOrdID
+	pending.Status = enum.OrdStatus_PENDING_CANCEL
+	if err = app.sendExecutionReport(pending, enum.ExecType_PENDING_CANCEL, nil); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", order.OrderID)
+	}
+
+	if err = app.engine.Cancel(order, clOrdID); err != nil {
+		app.sendOrderCancelReject(sessionID, order, clOrdID, origClOrdID, responseTo, enum.CxlRejReason_OTHER, err.Error())
+		return nil
+	}
+
+	if err = app.sendExecutionReport(*order, enum.ExecType_CANCELED, nil); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", order.OrderID)
+	}
+
+	app.publishMarketData(order.Symbol, nil)
+	app.persistOrder(*order)
+
+	app.triggerStops(order.Symbol)
+
+	return nil
+}
+
+func (app *Acceptor) onOrderCancelReplaceRequest(msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	clOrdID, ferr := msg.Body.GetString(tag.ClOrdID)
+	if ferr != nil {
+		return ferr
+	}
+
+	// The downstream OMS owns the order lifecycle
+	if app.natsBridge {
+		return app.forward(app.natsReplace, msg, sessionID)
+	}
+
+	if err := app.dispatch(msg, sessionID); err != nil {
+		return quickfix.NewMessageRejectError(err.Error(), int(tag.BusinessRejectReason), nil)
+	}
+
+	orderQty := field.OrderQtyField{}
+	if ferr = msg.Body.GetField(tag.OrderQty, &orderQty); ferr != nil {
+		return ferr
+	}
+
+	origClOrdID := utils.MustNot(msg.Body.GetString(tag.OrigClOrdID))
+	orderID := utils.MustNot(msg.Body.GetString(tag.OrderID))
+	responseTo := enum.CxlRejResponseTo_ORDER_CANCEL_REPLACE_REQUEST
+
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	order, err := app.engine.Lookup(sessionID, orderID, origClOrdID)
+	if err != nil {
+		app.sendOrderCancelReject(sessionID, nil, clOrdID, origClOrdID, responseTo, enum.CxlRejReason_UNKNOWN_ORDER, err.Error())
+		return nil
+	}
+
+	if order.IsFinal() {
+		app.sendOrderCancelReject(sessionID, order, clOrdID, origClOrdID, responseTo, enum.CxlRejReason_TOO_LATE_TO_CANCEL, errors.AcceptorOrderFinal.Error())
+		return nil
+	}
+
+	if _, err = app.checkPhase(order.Symbol); err != nil {
+		app.sendOrderCancelReject(sessionID, order, clOrdID, origClOrdID, responseTo, enum.CxlRejReason_OTHER, err.Error())
+		return nil
+	}
+
+	// Keep the current price if none is given
+	price := field.NewPrice(order.Price, 2)
+	if msg.Body.Has(tag.Price) {
+		if ferr = msg.Body.GetField(tag.Price, &price); ferr != nil {
+			return ferr
+		}
+	}
+
+	// Keep the current stop price if none is given
+	stopPx := field.NewStopPx(order.StopPx, 2)
+	if msg.Body.Has(tag.StopPx) {
+		if ferr = msg.Body.GetField(tag.StopPx, &stopPx); ferr != nil {
+			return ferr
+		}
+	}
+
+	if orderQty.Value().LessThanOrEqual(order.CumQty) {
+		app.sendOrderCancelReject(sessionID, order, clOrdID, origClOrdID, responseTo, enum.CxlRejReason_OTHER, errors.AcceptorOrderQuantityTooLow.Error())
+		return nil
+	}
+
+	// Snapshot the order before matching so that the replaced execution report
+	// comes before the trades it may trigger.
+	replaced := *order
+	replaced.OrigClOrdID = order.ClOrdID
+	replaced.ClOrdID = clOrdID
+	replaced.Quantity = orderQty.Value()
+	replaced.Price = price.Value()
+	replaced.StopPx = stopPx.Value()
+
+	// The amended order must fit the limits a new one would have to
+	if violation := app.checkRisk(&replaced); violation != nil {
+		app.sendOrderCancelReject(sessionID, order, clOrdID, origClOrdID, responseTo, enum.CxlRejReason_OTHER, violation.Error())
+		return nil
+	}
+
+	pending := *order
+	pending.OrigClOrdID = order.ClOrdID
+	pending.ClOrdID = clOrdID
+	pending.Status = enum.OrdStatus_PENDING_REPLACE
+	if err = app.sendExecutionReport(pending, enum.ExecType_PENDING_REPLACE, nil); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", order.OrderID)
+	}
+
+	fills, err := app.engine.Replace(order, clOrdID, orderQty.Value(), price.Value(), stopPx.Value())
+	if err != nil {
+		app.sendOrderCancelReject(sessionID, order, clOrdID, origClOrdID, responseTo, enum.CxlRejReason_OTHER, err.Error())
+		return nil
+	}
+
+	if err = app.sendExecutionReport(replaced, enum.ExecType_REPLACED, nil); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", order.OrderID)
+	}
+
+	app.sendFills(fills)
+	app.publishMarketData(order.Symbol, fills)
+	app.sendTradeCaptureReports(fills)
+
+	app.persistOrder(*order)
+	for _, fill := range fills {
+		app.persistOrder(fill.Resting)
+	}
+
+	app.triggerStops(order.Symbol)
+
+	return nil
+}
+
+// sendOrderCancelReject answers a cancel or cancel/replace request which can
+// not be processed. order is nil when the order is unknown.
+func (app *Acceptor) sendOrderCancelReject(sessionID quickfix.SessionID, order *engine.Order, clOrdID, origClOrdID string, responseTo enum.CxlRejResponseTo, reason enum.CxlRejReason, text string) {
+	message := newMessage(sessionID, enum.MsgType_ORDER_CANCEL_REJECT)
+
+	if order != nil {
+		message.Body.Set(field.NewOrderID(order.OrderID))
+		message.Body.Set(field.NewOrdStatus(order.Status))
+	} else {
+		message.Body.Set(field.NewOrderID("NONE"))
+		message.Body.Set(field.NewOrdStatus(enum.OrdStatus_REJECTED))
+	}
+
+	message.Body.Set(field.NewClOrdID(clOrdID))
+	utils.QuickFixMessagePartSetString(&message.Body, origClOrdID, field.NewOrigClOrdID)
+	message.Body.Set(field.NewCxlRejResponseTo(responseTo))
+	message.Body.Set(field.NewCxlRejReason(reason))
+	message.Body.Set(field.NewText(text))
+	message.Body.Set(field.NewTransactTime(time.Now()))
+
+	if err := quickfix.SendToTarget(message, sessionID); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send order cancel reject for %s", clOrdID)
+	}
+}
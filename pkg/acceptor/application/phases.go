@@ -0,0 +1,204 @@
+package application
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quickfixgo/enum"
+
+	"sylr.dev/fix/pkg/acceptor/refdata"
+	"sylr.dev/fix/pkg/errors"
+)
+
+// runSchedule moves the trading sessions through the phases of their schedule
+// every second until the acceptor is closed.
+func (app *Acceptor) runSchedule() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.done:
+			return
+		case now := <-ticker.C:
+			app.mux.Lock()
+			app.updatePhases(now)
+			app.mux.Unlock()
+		}
+	}
+}
+
+// updatePhases computes the phases of the trading sessions and the statuses
+// of the symbols and sends those which changed to the sessions subscribed to
+// them. It must be called with app.mux held.
+func (app *Acceptor) updatePhases(now time.Time) {
+	sessionPhases := make(map[string]refdata.Phase, len(app.refData.TradingSessions))
+	for _, session := range app.refData.TradingSessions {
+		phase := app.tradingSessionPhase(session, now)
+		sessionPhases[session.TradingSessionID] = phase
+
+		if previous, ok := app.sessionPhases[session.TradingSessionID]; ok && previous == phase {
+			continue
+		}
+
+		app.Logger.Info().Msgf("Trading session %s is %s", session.TradingSessionID, phase)
+
+		for sessionID, subs := range app.tradSesStatusSubscriptions {
+			for reqID, id := range subs {
+				if len(id) == 0 || id == session.TradingSessionID {
+					app.sendTradingSessionStatus(sessionID, reqID, session, phase)
+				}
+			}
+		}
+	}
+	app.sessionPhases = sessionPhases
+
+	symbolStatuses := make(map[string]enum.SecurityTradingStatus, len(app.refData.Instruments))
+	for _, instrument := range app.refData.Instruments {
+		phase := app.instrumentPhase(instrument, now)
+		app.setSymbolPhase(instrument.Symbol, phase)
+
+		status := instrumentStatus(instrument, phase)
+		symbolStatuses[instrument.Symbol] = status
+
+		if previous, ok := app.symbolStatuses[instrument.Symbol]; ok && previous == status {
+			continue
+		}
+
+		for sessionID, subs := range app.securityStatusSubscriptions {
+			for reqID, symbol := range subs {
+				if symbol == instrument.Symbol {
+					app.sendSecurityStatus(sessionID, reqID, instrument, status)
+				}
+			}
+		}
+	}
+	app.symbolStatuses = symbolStatuses
+}
+
+// tradingSessionPhase returns the phase forced through the admin API or the
+// scheduled one. It must be called with app.mux held.
+func (app *Acceptor) tradingSessionPhase(session *refdata.TradingSession, now time.Time) refdata.Phase {
+	if phase, ok := app.phaseOverrides[session.TradingSessionID]; ok {
+		return phase
+	}
+	return session.Phase(now)
+}
+
+// instrumentPhase returns the phase of the symbol: halted if it was halted
+// through the admin API, the one of its status if any, the one forced on its
+// trading session, the one of its own schedule or the one of its trading
+// session. It must be called with app.mux held.
+func (app *Acceptor) instrumentPhase(instrument *refdata.Instrument, now time.Time) refdata.Phase {
+	if app.halts[instrument.Symbol] {
+		return refdata.PhaseHalted
+	}
+
+	if phase := instrument.Phase(); len(phase) > 0 {
+		return phase
+	}
+
+	if phase, ok := app.phaseOverrides[instrument.TradingSessionID]; ok {
+		return phase
+	}
+
+	if phase := instrument.ScheduledPhase(now); len(phase) > 0 {
+		return phase
+	}
+
+	if session := app.refData.TradingSession(instrument.TradingSessionID); session != nil {
+		return session.Phase(now)
+	}
+
+	return refdata.PhaseContinuous
+}
+
+// setSymbolPhase records the phase of the symbol. Its book starts collecting
+// orders when an auction begins and is uncrossed when the symbol trades
+// continuously or closes. It must be called with app.mux held.
+func (app *Acceptor) setSymbolPhase(symbol string, phase refdata.Phase) {
+	if previous, ok := app.symbolPhases[symbol]; ok && previous == phase {
+		return
+	}
+	app.symbolPhases[symbol] = phase
+
+	switch {
+	case phase.IsAuction():
+		app.engine.StartAuction(symbol)
+	case phase == refdata.PhaseContinuous, phase == refdata.PhaseClosed:
+		if app.engine.InAuction(symbol) {
+			app.uncross(symbol)
+		}
+	}
+}
+
+// instrumentStatus returns the status of the symbol in the phase, instruments
+// trading continuously keep the one of their reference data.
+func instrumentStatus(instrument *refdata.Instrument, phase refdata.Phase) enum.SecurityTradingStatus {
+	if phase != refdata.PhaseContinuous {
+		return phase.SecurityTradingStatus()
+	}
+	return instrument.TradingStatus()
+}
+
+// checkPhase returns the phase of the symbol or an error if it does not
+// accept orders, symbols missing from the reference data always do. It must be
+// called with app.mux held.
+func (app *Acceptor) checkPhase(symbol string) (refdata.Phase, error) {
+	instrument := app.refData.Instrument(symbol)
+	if instrument == nil {
+		return refdata.PhaseContinuous, nil
+	}
+
+	// Symbols are only moved to their current phase every second, make sure
+	// the book is in the phase orders are checked against.
+	phase := app.instrumentPhase(instrument, time.Now())
+	app.setSymbolPhase(symbol, phase)
+
+	if !phase.AcceptsOrders() {
+		return phase, fmt.Errorf("%w: %s", errors.AcceptorOrderNotAccepted, phase)
+	}
+
+	return phase, nil
+}
+
+// setTradingSessionPhase forces the phase of the trading session until it is
+// called with an empty phase, which brings the session back to its schedule.
+func (app *Acceptor) setTradingSessionPhase(tradingSessionID string, phase refdata.Phase) error {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	if app.refData.TradingSession(tradingSessionID) == nil {
+		return fmt.Errorf("%w: %s", errors.RefDataUnknownTradingSession, tradingSessionID)
+	}
+
+	if len(phase) == 0 {
+		delete(app.phaseOverrides, tradingSessionID)
+	} else {
+		app.phaseOverrides[tradingSessionID] = phase
+	}
+
+	app.updatePhases(time.Now())
+
+	return nil
+}
+
+// setHalted halts or resumes trading on the symbol.
+func (app *Acceptor) setHalted(symbol string, halted bool) error {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	if app.refData.Instrument(symbol) == nil {
+		return fmt.Errorf("%w: %s", errors.RefDataUnknownSymbol, symbol)
+	}
+
+	if halted {
+		app.halts[symbol] = true
+	} else {
+		delete(app.halts, symbol)
+	}
+
+	app.updatePhases(time.Now())
+
+	return nil
+}
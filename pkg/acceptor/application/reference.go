@@ -0,0 +1,246 @@
+package application
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/acceptor/refdata"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/utils"
+)
+
+// SetReferenceData replaces the reference data served by the acceptor and
+// sends the statuses which changed to the sessions subscribed to them.
+func (app *Acceptor) SetReferenceData(data *refdata.Data) {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	app.refData = data
+	app.updatePhases(time.Now())
+}
+
+func (app *Acceptor) onSecurityListRequest(msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	reqID, ferr := msg.Body.GetString(tag.SecurityReqID)
+	if ferr != nil {
+		return ferr
+	}
+
+	requestType := field.SecurityListRequestTypeField{}
+	if ferr = msg.Body.GetField(tag.SecurityListRequestType, &requestType); ferr != nil {
+		return ferr
+	}
+
+	symbol := utils.MustNot(msg.Body.GetString(tag.Symbol))
+	securityType := utils.MustNot(msg.Body.GetString(tag.SecurityType))
+	cfiCode := utils.MustNot(msg.Body.GetString(tag.CFICode))
+	tradingSessionID := utils.MustNot(msg.Body.GetString(tag.TradingSessionID))
+
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	result := enum.SecurityRequestResult_VALID_REQUEST
+	instruments := make([]*refdata.Instrument, 0, len(app.refData.Instruments))
+
+	// Empty criteria match every instrument
+	for _, instrument := range app.refData.Instruments {
+		var match bool
+		switch requestType.Value() {
+		case enum.SecurityListRequestType_SYMBOL:
+			match = len(symbol) == 0 || instrument.Symbol == symbol
+		case enum.SecurityListRequestType_SECURITYTYPE_AND_OR_CFICODE:
+			match = (len(securityType) == 0 || instrument.SecurityType == securityType) &&
+				(len(cfiCode) == 0 || instrument.CFICode == cfiCode)
+		case enum.SecurityListRequestType_TRADINGSESSIONID:
+			match = len(tradingSessionID) == 0 || instrument.TradingSessionID == tradingSessionID
+		case enum.SecurityListRequestType_ALL_SECURITIES:
+			match = true
+		default:
+			result = enum.SecurityRequestResult_INVALID_OR_UNSUPPORTED_REQUEST
+		}
+
+		if match {
+			instruments = append(instruments, instrument)
+		}
+	}
+
+	if result == enum.SecurityRequestResult_VALID_REQUEST && len(instruments) == 0 {
+		result = enum.SecurityRequestResult_NO_INSTRUMENTS_FOUND
+	}
+
+	message := newMessage(sessionID, enum.MsgType_SECURITY_LIST)
+
+	message.Body.Set(field.NewSecurityReqID(reqID))
+	message.Body.Set(field.NewSecurityResponseID(uuid.NewString()))
+	message.Body.Set(field.NewSecurityRequestResult(result))
+	message.Body.Set(field.NewTotNoRelatedSym(len(instruments)))
+	message.Body.Set(field.NewLastFragment(true))
+
+	relatedSym := quickfix.NewRepeatingGroup(
+		tag.NoRelatedSym,
+		quickfix.GroupTemplate{
+			quickfix.GroupElement(tag.Symbol),
+			quickfix.GroupElement(tag.SecurityID),
+			quickfix.GroupElement(tag.SecurityIDSource),
+			quickfix.GroupElement(tag.CFICode),
+			quickfix.GroupElement(tag.SecurityType),
+			quickfix.GroupElement(tag.Currency),
+		},
+	)
+
+	for _, instrument := range instruments {
+		entry := relatedSym.Add()
+		entry.Set(field.NewSymbol(instrument.Symbol))
+		utils.QuickFixMessagePartSetString(entry, instrument.SecurityID, field.NewSecurityID)
+		utils.QuickFixMessagePartSetString(entry, enum.SecurityIDSource(instrument.SecurityIDSource), field.NewSecurityIDSource)
+		utils.QuickFixMessagePartSetString(entry, instrument.CFICode, field.NewCFICode)
+		utils.QuickFixMessagePartSetString(entry, enum.SecurityType(instrument.SecurityType), field.NewSecurityType)
+		utils.QuickFixMessagePartSetString(entry, instrument.Currency, field.NewCurrency)
+	}
+
+	if len(instruments) > 0 {
+		message.Body.SetGroup(relatedSym)
+	}
+
+	if err := quickfix.SendToTarget(message, sessionID); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send security list for %s", reqID)
+	}
+
+	return nil
+}
+
+func (app *Acceptor) onSecurityStatusRequest(msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	symbol, ferr := msg.Body.GetString(tag.Symbol)
+	if ferr != nil {
+		return ferr
+	}
+
+	subType := field.SubscriptionRequestTypeField{}
+	if ferr = msg.Body.GetField(tag.SubscriptionRequestType, &subType); ferr != nil {
+		return ferr
+	}
+
+	reqID := utils.MustNot(msg.Body.GetString(tag.SecurityStatusReqID))
+
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	if subType.Value() == enum.SubscriptionRequestType_DISABLE_PREVIOUS_SNAPSHOT_PLUS_UPDATE_REQUEST {
+		delete(app.securityStatusSubscriptions[sessionID], reqID)
+		return nil
+	}
+
+	instrument := app.refData.Instrument(symbol)
+	if instrument == nil {
+		app.sendBusinessMessageReject(sessionID, enum.MsgType_SECURITY_STATUS_REQUEST, reqID, enum.BusinessRejectReason_UNKNOWN_SECURITY, errors.RefDataUnknownSymbol.Error())
+		return nil
+	}
+
+	if subType.Value() == enum.SubscriptionRequestType_SNAPSHOT_PLUS_UPDATES {
+		if _, ok := app.securityStatusSubscriptions[sessionID]; !ok {
+			app.securityStatusSubscriptions[sessionID] = make(map[string]string)
+		}
+		app.securityStatusSubscriptions[sessionID][reqID] = symbol
+	}
+
+	app.sendSecurityStatus(sessionID, reqID, instrument, instrumentStatus(instrument, app.instrumentPhase(instrument, time.Now())))
+
+	return nil
+}
+
+func (app *Acceptor) onTradingSessionStatusRequest(msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	subType := field.SubscriptionRequestTypeField{}
+	if ferr := msg.Body.GetField(tag.SubscriptionRequestType, &subType); ferr != nil {
+		return ferr
+	}
+
+	reqID := utils.MustNot(msg.Body.GetString(tag.TradSesReqID))
+	tradingSessionID := utils.MustNot(msg.Body.GetString(tag.TradingSessionID))
+
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	if subType.Value() == enum.SubscriptionRequestType_DISABLE_PREVIOUS_SNAPSHOT_PLUS_UPDATE_REQUEST {
+		delete(app.tradSesStatusSubscriptions[sessionID], reqID)
+		return nil
+	}
+
+	// No trading session id means all trading sessions
+	var sessions []*refdata.TradingSession
+	if len(tradingSessionID) > 0 {
+		if session := app.refData.TradingSession(tradingSessionID); session != nil {
+			sessions = append(sessions, session)
+		}
+	} else {
+		sessions = app.refData.TradingSessions
+	}
+
+	if len(sessions) == 0 {
+		app.sendTradingSessionStatusReject(sessionID, reqID, tradingSessionID)
+		return nil
+	}
+
+	if subType.Value() == enum.SubscriptionRequestType_SNAPSHOT_PLUS_UPDATES {
+		if _, ok := app.tradSesStatusSubscriptions[sessionID]; !ok {
+			app.tradSesStatusSubscriptions[sessionID] = make(map[string]string)
+		}
+		app.tradSesStatusSubscriptions[sessionID][reqID] = tradingSessionID
+	}
+
+	now := time.Now()
+	for _, session := range sessions {
+		app.sendTradingSessionStatus(sessionID, reqID, session, app.tradingSessionPhase(session, now))
+	}
+
+	return nil
+}
+
+func (app *Acceptor) sendSecurityStatus(sessionID quickfix.SessionID, reqID string, instrument *refdata.Instrument, status enum.SecurityTradingStatus) {
+	message := newMessage(sessionID, enum.MsgType_SECURITY_STATUS)
+
+	utils.QuickFixMessagePartSetString(&message.Body, reqID, field.NewSecurityStatusReqID)
+	message.Body.Set(field.NewSymbol(instrument.Symbol))
+	utils.QuickFixMessagePartSetString(&message.Body, instrument.SecurityID, field.NewSecurityID)
+	utils.QuickFixMessagePartSetString(&message.Body, enum.SecurityIDSource(instrument.SecurityIDSource), field.NewSecurityIDSource)
+	utils.QuickFixMessagePartSetString(&message.Body, instrument.Currency, field.NewCurrency)
+	utils.QuickFixMessagePartSetString(&message.Body, enum.TradingSessionID(instrument.TradingSessionID), field.NewTradingSessionID)
+	message.Body.Set(field.NewSecurityTradingStatus(status))
+	message.Body.Set(field.NewTransactTime(time.Now()))
+
+	if err := quickfix.SendToTarget(message, sessionID); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send security status for %s", instrument.Symbol)
+	}
+}
+
+func (app *Acceptor) sendTradingSessionStatus(sessionID quickfix.SessionID, reqID string, session *refdata.TradingSession, phase refdata.Phase) {
+	message := newMessage(sessionID, enum.MsgType_TRADING_SESSION_STATUS)
+
+	utils.QuickFixMessagePartSetString(&message.Body, reqID, field.NewTradSesReqID)
+	message.Body.Set(field.NewTradingSessionID(enum.TradingSessionID(session.TradingSessionID)))
+	utils.QuickFixMessagePartSetString(&message.Body, enum.TradingSessionSubID(session.TradingSessionSubID), field.NewTradingSessionSubID)
+	message.Body.Set(field.NewTradSesStatus(phase.TradSesStatus()))
+	message.Body.Set(field.NewTransactTime(time.Now()))
+
+	if err := quickfix.SendToTarget(message, sessionID); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send trading session status for %s", session.TradingSessionID)
+	}
+}
+
+func (app *Acceptor) sendTradingSessionStatusReject(sessionID quickfix.SessionID, reqID, tradingSessionID string) {
+	message := newMessage(sessionID, enum.MsgType_TRADING_SESSION_STATUS)
+
+	utils.QuickFixMessagePartSetString(&message.Body, reqID, field.NewTradSesReqID)
+	utils.QuickFixMessagePartSetString(&message.Body, enum.TradingSessionID(tradingSessionID), field.NewTradingSessionID)
+	message.Body.Set(field.NewTradSesStatus(enum.TradSesStatus_REQUEST_REJECTED))
+	message.Body.Set(field.NewTradSesStatusRejReason(enum.TradSesStatusRejReason_UNKNOWN_OR_INVALID_TRADINGSESSIONID))
+	message.Body.Set(field.NewText(errors.RefDataUnknownTradingSession.Error()))
+
+	if err := quickfix.SendToTarget(message, sessionID); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send trading session status for %s", tradingSessionID)
+	}
+}
@@ -0,0 +1,44 @@
+package application
+
+import (
+	"sylr.dev/fix/pkg/acceptor/engine"
+	"sylr.dev/fix/pkg/acceptor/risk"
+)
+
+// SetRiskLimits replaces the pre-trade limits of the acceptor.
+func (app *Acceptor) SetRiskLimits(limits *risk.Config) {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	app.riskLimits = limits
+}
+
+// checkRisk returns the first limit applying to the order session it breaches,
+// the order itself does not count among the open orders when it is amended.
+// app.mux must be held until the order is submitted to the engine.
+func (app *Acceptor) checkRisk(order *engine.Order) *risk.Violation {
+	limits, bySenderCompID := app.riskLimits.Limits(order.SessionID)
+	reference := app.referencePrice(order.Symbol)
+
+	if limits == nil {
+		return nil
+	}
+
+	openOrders := 0
+	if limits.MaxOpenOrders > 0 {
+		openOrders = app.engine.OpenOrders(func(o *engine.Order) bool {
+			if o.OrderID == order.OrderID {
+				return false
+			}
+			if bySenderCompID {
+				return o.SessionID.TargetCompID == order.SessionID.TargetCompID
+			}
+			return o.SessionID == order.SessionID
+		})
+	}
+
+	violation := limits.Check(order, reference, openOrders)
+	risk.Record(order.SessionID, violation)
+
+	return violation
+}
@@ -0,0 +1,119 @@
+package application
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/acceptor/rules"
+	"sylr.dev/fix/pkg/utils"
+)
+
+// SetRules replaces the behaviour rules of the acceptor.
+func (app *Acceptor) SetRules(r *rules.Rules) {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	app.rules = r
+}
+
+// applyRule responds to a message the way the rule it matched says. Delayed
+// responses are sent from a timer so that the session keeps processing the
+// messages coming after.
+func (app *Acceptor) applyRule(rule *rules.Rule, msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	msgType, ferr := msg.MsgType()
+	if ferr != nil {
+		return ferr
+	}
+
+	app.Logger.Debug().Msgf("Message %s matched rule %s", msgType, rule.Name)
+
+	if rule.Delay <= 0 || rule.Action == rules.ActionDrop {
+		return app.applyRuleAction(rule, msg, enum.MsgType(msgType), sessionID)
+	}
+
+	time.AfterFunc(rule.Delay, func() {
+		// The message can no longer be rejected by the session, the reject
+		// is sent as a BusinessMessageReject instead.
+		rej := app.applyRuleAction(rule, msg, enum.MsgType(msgType), sessionID)
+		if rej == nil {
+			return
+		}
+
+		reason := enum.BusinessRejectReason_OTHER
+		if rej.IsBusinessReject() {
+			reason = enum.BusinessRejectReason(strconv.Itoa(rej.RejectReason()))
+		}
+
+		refID := utils.MustNot(msg.Body.GetString(tag.ClOrdID))
+		app.sendBusinessMessageReject(sessionID, enum.MsgType(msgType), refID, reason, rej.Error())
+	})
+
+	return nil
+}
+
+// applyRuleAction carries out the action of the rule. It must be called
+// without app.mux held as the message handlers take it.
+func (app *Acceptor) applyRuleAction(rule *rules.Rule, msg *quickfix.Message, msgType enum.MsgType, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	switch rule.Action {
+	case rules.ActionDrop:
+		return nil
+
+	case rules.ActionBusinessReject:
+		refID := utils.MustNot(msg.Body.GetString(tag.ClOrdID))
+		app.sendBusinessMessageReject(sessionID, msgType, refID, rule.GetBusinessRejectReason(), rule.GetText())
+		return nil
+
+	case rules.ActionReject:
+		switch msgType {
+		case enum.MsgType_ORDER_SINGLE:
+			return app.newOrderSingle(msg, sessionID, rule)
+		case enum.MsgType_ORDER_CANCEL_REQUEST, enum.MsgType_ORDER_CANCEL_REPLACE_REQUEST:
+			return app.rejectOrderCancel(rule, msg, msgType, sessionID)
+		}
+
+		refID := utils.MustNot(msg.Body.GetString(tag.ClOrdID))
+		app.sendBusinessMessageReject(sessionID, msgType, refID, rule.GetBusinessRejectReason(), rule.GetText())
+		return nil
+
+	case rules.ActionPartialFill:
+		if msgType == enum.MsgType_ORDER_SINGLE {
+			return app.newOrderSingle(msg, sessionID, rule)
+		}
+	}
+
+	return app.router.Route(msg, sessionID)
+}
+
+// rejectOrderCancel answers a cancel or cancel/replace request matched by a
+// reject rule with an OrderCancelReject.
+func (app *Acceptor) rejectOrderCancel(rule *rules.Rule, msg *quickfix.Message, msgType enum.MsgType, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	clOrdID, ferr := msg.Body.GetString(tag.ClOrdID)
+	if ferr != nil {
+		return ferr
+	}
+
+	origClOrdID := utils.MustNot(msg.Body.GetString(tag.OrigClOrdID))
+	orderID := utils.MustNot(msg.Body.GetString(tag.OrderID))
+
+	responseTo := enum.CxlRejResponseTo_ORDER_CANCEL_REQUEST
+	if msgType == enum.MsgType_ORDER_CANCEL_REPLACE_REQUEST {
+		responseTo = enum.CxlRejResponseTo_ORDER_CANCEL_REPLACE_REQUEST
+	}
+
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	reason := enum.CxlRejReason_OTHER
+	order, err := app.engine.Lookup(sessionID, orderID, origClOrdID)
+	if err != nil {
+		reason = enum.CxlRejReason_UNKNOWN_ORDER
+	}
+
+	app.sendOrderCancelReject(sessionID, order, clOrdID, origClOrdID, responseTo, reason, rule.GetText())
+
+	return nil
+}
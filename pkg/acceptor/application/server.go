@@ -1,20 +1,30 @@
 package application
 
 import (
-	"bytes"
+	"net"
+	"strconv"
+	"sync"
 	"text/template"
+	"time"
 
+	"github.com/google/uuid"
 	natsd "github.com/nats-io/nats-server/v2/server"
 	"github.com/nats-io/nats.go"
 	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
 
 	"github.com/quickfixgo/enum"
 	"github.com/quickfixgo/field"
-	"github.com/quickfixgo/fixt11"
 	"github.com/quickfixgo/quickfix"
 	"github.com/quickfixgo/tag"
 
-	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/acceptor/engine"
+	"sylr.dev/fix/pkg/acceptor/gtc"
+	"sylr.dev/fix/pkg/acceptor/refdata"
+	"sylr.dev/fix/pkg/acceptor/risk"
+	"sylr.dev/fix/pkg/acceptor/rules"
+	"sylr.dev/fix/pkg/acceptor/sink"
+	"sylr.dev/fix/pkg/errors"
 	"sylr.dev/fix/pkg/utils"
 )
 
@@ -22,6 +32,27 @@ type AcceptorOptions struct {
 	NATSEmbeded      bool
 	NATSURL          string
 	NATSOrderSubject string
+	ReferenceData    *refdata.Data
+	Rules            *rules.Rules
+	RiskLimits       *risk.Config
+	FillMode         FillMode
+	StopTrigger      StopTrigger
+
+	// GTCStore persists good till cancel orders, they are restored in the
+	// book when the acceptor is created.
+	GTCStore *gtc.Store
+
+	// Sinks receive the incoming messages, orders are published on
+	// NATSOrderSubject if there is none.
+	Sinks []sink.Sink
+
+	// In bridge mode orders, cancels and replaces are only published to NATS
+	// and the responses of the downstream OMS are read from the execution
+	// subject.
+	NATSBridge           bool
+	NATSCancelSubject    string
+	NATSReplaceSubject   string
+	NATSExecutionSubject string
 }
 
 func NewAcceptor(options *AcceptorOptions) (*Acceptor, error) {
@@ -34,8 +65,54 @@ func NewAcceptor(options *AcceptorOptions) (*Acceptor, error) {
 	}
 
 	s := Acceptor{
-		NatsOrderSubject: tpl,
-		router:           quickfix.NewMessageRouter(),
+		router:          quickfix.NewMessageRouter(),
+		engine:          engine.NewEngine(),
+		mdSubscriptions: make(map[quickfix.SessionID]map[string]*mdSubscription),
+		sessions:        make(map[string]quickfix.SessionID),
+		logonFailures:   make(map[quickfix.SessionID]int),
+		loggedOn:        make(map[quickfix.SessionID]bool),
+		stores:          make(map[quickfix.SessionID]*lockedMessageStore),
+		conns:           make(map[quickfix.SessionID]net.Conn),
+		logoutTimers:    make(map[quickfix.SessionID]*time.Timer),
+		throttles:       make(map[quickfix.SessionID]*throttle),
+		codTimers:       make(map[quickfix.SessionID]*time.Timer),
+		pendingReports:  make(map[quickfix.SessionID][]*quickfix.Message),
+		refData:         options.ReferenceData,
+		rules:           options.Rules,
+		riskLimits:      options.RiskLimits,
+		fillMode:        options.FillMode,
+		stopTrigger:     options.StopTrigger,
+		externalPrices:  make(map[string]decimal.Decimal),
+		gtcStore:        options.GTCStore,
+		restoredStops:   make(map[string]bool),
+		done:            make(chan struct{}),
+		natsClosed:      make(chan struct{}),
+
+		dropCopySessions:            make(map[quickfix.SessionID]bool),
+		postTradeSessions:           make(map[quickfix.SessionID]bool),
+		securityStatusSubscriptions: make(map[quickfix.SessionID]map[string]string),
+		tradSesStatusSubscriptions:  make(map[quickfix.SessionID]map[string]string),
+
+		symbolPhases:   make(map[string]refdata.Phase),
+		phaseOverrides: make(map[string]refdata.Phase),
+		halts:          make(map[string]bool),
+	}
+
+	if s.refData == nil {
+		s.refData = &refdata.Data{}
+	}
+
+	if s.gtcStore != nil {
+		orders, err := s.gtcStore.Load()
+		if err != nil {
+			return nil, err
+		}
+		for _, order := range orders {
+			s.engine.Restore(order)
+			if order.IsArmed() {
+				s.restoredStops[order.Symbol] = true
+			}
+		}
 	}
 
 	if options.NATSEmbeded {
@@ -47,67 +124,228 @@ func NewAcceptor(options *AcceptorOptions) (*Acceptor, error) {
 		}
 	}
 
-	natsOptions := []nats.Option{
-		nats.DontRandomize(),
-		nats.RetryOnFailedConnect(true),
+	// NATS is only needed by the default sink and the bridge
+	if options.NATSBridge || len(options.Sinks) == 0 {
+		natsOptions := []nats.Option{
+			nats.DontRandomize(),
+			nats.RetryOnFailedConnect(true),
+			nats.ClosedHandler(func(*nats.Conn) { close(s.natsClosed) }),
+		}
+		s.natsConn, err = nats.Connect(options.NATSURL, natsOptions...)
+		if err != nil {
+			return nil, err
+		}
 	}
-	s.natsConn, err = nats.Connect(options.NATSURL, natsOptions...)
-	if err != nil {
-		return nil, err
+
+	s.sinks = options.Sinks
+	if len(s.sinks) == 0 {
+		s.sinks = []sink.Sink{sink.Require(sink.Filter(sink.NewNATS(s.natsConn, tpl), string(enum.MsgType_ORDER_SINGLE)))}
+	}
+
+	if options.NATSBridge {
+		if err = s.startBridge(options, tpl); err != nil {
+			return nil, err
+		}
 	}
 
-	//s.router.AddRoute(fix50sp2nos.Route(s.onNewOrderSingle))
-	s.router.AddRoute(quickfix.ApplVerIDFIX50SP2, string(enum.MsgType_ORDER_SINGLE), s.onNewOrderSingle)
+	for _, version := range acceptorVersions {
+		s.router.AddRoute(version, string(enum.MsgType_ORDER_SINGLE), s.onNewOrderSingle)
+		s.router.AddRoute(version, string(enum.MsgType_ORDER_CANCEL_REQUEST), s.onOrderCancelRequest)
+		s.router.AddRoute(version, string(enum.MsgType_ORDER_CANCEL_REPLACE_REQUEST), s.onOrderCancelReplaceRequest)
+		s.router.AddRoute(version, string(enum.MsgType_MARKET_DATA_REQUEST), s.onMarketDataRequest)
+		s.router.AddRoute(version, string(enum.MsgType_SECURITY_STATUS_REQUEST), s.onSecurityStatusRequest)
+		s.router.AddRoute(version, string(enum.MsgType_TRADING_SESSION_STATUS_REQUEST), s.onTradingSessionStatusRequest)
+
+		// SecurityListRequest and TradeCaptureReportRequest were introduced
+		// by FIX.4.3
+		if version != quickfix.BeginStringFIX42 {
+			s.router.AddRoute(version, string(enum.MsgType_SECURITY_LIST_REQUEST), s.onSecurityListRequest)
+			s.router.AddRoute(version, string(enum.MsgType_TRADE_CAPTURE_REPORT_REQUEST), s.onTradeCaptureReportRequest)
+		}
+	}
 
 	return &s, nil
 }
 
-type NewOrderSingleNatsSubject struct {
-	Symbol string
-	Side   string
-	Type   string
+// Start expires the orders and follows the trading schedule in the
+// background, it must be called once the acceptor is configured.
+func (app *Acceptor) Start() {
+	app.wg.Add(2)
+	go func() {
+		defer app.wg.Done()
+		app.expireOrders()
+	}()
+	go func() {
+		defer app.wg.Done()
+		app.runSchedule()
+	}()
 }
 
 type Acceptor struct {
 	utils.QuickFixAppMessageLogger
 
 	natsConn   *nats.Conn
+	natsClosed chan struct{}
 	natsServer *natsd.Server
 
-	NatsOrderSubject *template.Template
-	router           *quickfix.MessageRouter
-	Settings         *quickfix.Settings
+	natsBridge  bool
+	natsOrders  sink.Sink
+	natsCancels sink.Sink
+	natsReplace sink.Sink
+
+	sinks           []sink.Sink
+	router          *quickfix.MessageRouter
+	engine          *engine.Engine
+	mdSubscriptions map[quickfix.SessionID]map[string]*mdSubscription
+	sessions        map[string]quickfix.SessionID
+	logonFailures   map[quickfix.SessionID]int
+	loggedOn        map[quickfix.SessionID]bool
+	stores          map[quickfix.SessionID]*lockedMessageStore
+	conns           map[quickfix.SessionID]net.Conn
+	logoutTimers    map[quickfix.SessionID]*time.Timer
+	throttles       map[quickfix.SessionID]*throttle
+	codTimers       map[quickfix.SessionID]*time.Timer
+	pendingReports  map[quickfix.SessionID][]*quickfix.Message
+	refData         *refdata.Data
+	rules           *rules.Rules
+	riskLimits      *risk.Config
+	fillMode        FillMode
+	stopTrigger     StopTrigger
+	externalPrices  map[string]decimal.Decimal
+	gtcStore        *gtc.Store
+	restoredStops   map[string]bool
+	done            chan struct{}
+	wg              sync.WaitGroup
+	mux             sync.Mutex
+	Settings        *quickfix.Settings
+	DynamicSettings *quickfix.SessionSettings
+
+	// Data dictionaries by BeginString, the embeded ones are used for the
+	// versions missing.
+	Dictionaries map[string]Dictionaries
+
+	// Drop-copy sessions and whether they are logged on, they have their own
+	// lock as copies are sent while mux is held.
+	dropCopySessions map[quickfix.SessionID]bool
+	dropCopyMux      sync.RWMutex
+
+	// Post-trade sessions and whether they are logged on, they have their own
+	// lock as trade capture reports are sent while mux is held.
+	postTradeSessions map[quickfix.SessionID]bool
+	postTradeMux      sync.RWMutex
+
+	// Subscribed symbols and trading session ids by request id
+	securityStatusSubscriptions map[quickfix.SessionID]map[string]string
+	tradSesStatusSubscriptions  map[quickfix.SessionID]map[string]string
+
+	// Trading phases last sent by trading session id, phases and statuses of
+	// the symbols, phases forced through the admin API and halted symbols.
+	sessionPhases  map[string]refdata.Phase
+	symbolPhases   map[string]refdata.Phase
+	symbolStatuses map[string]enum.SecurityTradingStatus
+	phaseOverrides map[string]refdata.Phase
+	halts          map[string]bool
 }
 
+// Close stops the background goroutines and drains the NATS connection, so
+// that no message reaches the sinks and the GTC order store once they are
+// closed. It must be called once the sessions are stopped.
 func (app *Acceptor) Close() {
-	app.natsConn.Close()
-	app.natsServer.Shutdown()
+	close(app.done)
+	app.wg.Wait()
+
+	if app.natsConn != nil {
+		if err := app.natsConn.Drain(); err != nil {
+			app.natsConn.Close()
+		}
+		<-app.natsClosed
+	}
+
+	for _, s := range app.sinks {
+		if err := s.Close(); err != nil {
+			app.Logger.Error().Err(err).Msg("Unable to close sink")
+		}
+	}
+
+	if app.gtcStore != nil {
+		if err := app.gtcStore.Close(); err != nil {
+			app.Logger.Error().Err(err).Msg("Unable to close GTC order store")
+		}
+	}
+
+	if app.natsServer != nil {
+		app.natsServer.Shutdown()
+	}
 }
 
 // Notification of a session begin created.
 func (app *Acceptor) OnCreate(sessionID quickfix.SessionID) {
-	app.Logger.Debug().Msgf("New session: %s", sessionID)
+	if _, dynamic := app.sessionSettings(sessionID); dynamic {
+		app.Logger.Info().Msgf("New dynamic session: %s", sessionID)
+		metricDynamicSessions.WithLabelValues(sessionID.String()).Inc()
+	} else {
+		app.Logger.Debug().Msgf("New session: %s", sessionID)
+	}
+
+	app.mux.Lock()
+	app.sessions[sessionID.String()] = sessionID
+	app.mux.Unlock()
+
+	app.registerDropCopySession(sessionID)
+	app.registerPostTradeSession(sessionID)
+	app.registerThrottle(sessionID)
 }
 
 // Notification of a session successfully logging on.
 func (app *Acceptor) OnLogon(sessionID quickfix.SessionID) {
 	app.Logger.Debug().Msgf("Logon: %s", sessionID)
+
+	app.mux.Lock()
+	app.loggedOn[sessionID] = true
+	app.mux.Unlock()
+
+	app.setDropCopyLoggedOn(sessionID, true)
+	app.setPostTradeLoggedOn(sessionID, true)
+	app.onReconnect(sessionID)
+
+	_, dynamic := app.sessionSettings(sessionID)
+	metricSessionsLoggedOn.WithLabelValues(sessionID.String(), strconv.FormatBool(dynamic)).Set(1)
 }
 
 // Notification of a session logging off or disconnecting.
 func (app *Acceptor) OnLogout(sessionID quickfix.SessionID) {
 	app.Logger.Debug().Msgf("Logout: %s", sessionID)
+
+	_, dynamic := app.sessionSettings(sessionID)
+	metricSessionsLoggedOn.WithLabelValues(sessionID.String(), strconv.FormatBool(dynamic)).Set(0)
+
+	app.setDropCopyLoggedOn(sessionID, false)
+	app.setPostTradeLoggedOn(sessionID, false)
+
+	app.mux.Lock()
+	delete(app.loggedOn, sessionID)
+	app.forgetConnection(sessionID)
+	delete(app.mdSubscriptions, sessionID)
+	delete(app.securityStatusSubscriptions, sessionID)
+	delete(app.tradSesStatusSubscriptions, sessionID)
+	app.mux.Unlock()
+
+	app.onDisconnect(sessionID)
 }
 
 // Notification of admin message being sent to target.
 func (app *Acceptor) ToAdmin(message *quickfix.Message, sessionID quickfix.SessionID) {
 	app.Logger.Debug().Msgf("-> Sending message to admin")
 
-	_, err := message.MsgType()
+	msgType, err := message.MsgType()
 	if err != nil {
 		app.Logger.Error().Msgf("Message type error: %s", err)
 	}
 
+	if err == nil && msgType == string(enum.MsgType_LOGOUT) {
+		app.setLogoutSessionStatus(message, sessionID)
+	}
+
 	app.LogMessage(zerolog.TraceLevel, message, sessionID, true)
 }
 
@@ -115,13 +353,21 @@ func (app *Acceptor) ToAdmin(message *quickfix.Message, sessionID quickfix.Sessi
 func (app *Acceptor) FromAdmin(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
 	app.Logger.Debug().Msgf("<- Message received from admin %s", sessionID)
 
-	_, err := message.MsgType()
+	msgType, err := message.MsgType()
 	if err != nil {
 		app.Logger.Error().Msgf("Message type error: %s", err)
 	}
 
 	app.LogMessage(zerolog.TraceLevel, message, sessionID, false)
 
+	if err == nil && msgType == string(enum.MsgType_LOGON) {
+		return app.checkLogon(message, sessionID)
+	}
+
+	if err == nil && msgType == string(enum.MsgType_LOGOUT) {
+		app.onLogoutResponse(sessionID)
+	}
+
 	return nil
 }
 
@@ -129,13 +375,17 @@ func (app *Acceptor) FromAdmin(message *quickfix.Message, sessionID quickfix.Ses
 func (app *Acceptor) ToApp(message *quickfix.Message, sessionID quickfix.SessionID) error {
 	app.Logger.Debug().Msgf("-> Sending message to app")
 
-	_, err := message.MsgType()
+	msgType, err := message.MsgType()
 	if err != nil {
 		app.Logger.Error().Msgf("Message type error: %s", err)
 	}
 
 	app.LogMessage(zerolog.TraceLevel, message, sessionID, true)
 
+	if err == nil && msgType == string(enum.MsgType_EXECUTION_REPORT) && !app.isDropCopySession(sessionID) {
+		app.dropCopy(message, sessionID)
+	}
+
 	return nil
 }
 
@@ -143,17 +393,46 @@ func (app *Acceptor) ToApp(message *quickfix.Message, sessionID quickfix.Session
 func (app *Acceptor) FromApp(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
 	app.Logger.Debug().Msgf("<- Message received from app %s", sessionID)
 
-	_, err := message.MsgType()
+	msgType, err := message.MsgType()
 	if err != nil {
 		app.Logger.Error().Msgf("Message type error: %s", err)
 	}
 
 	app.LogMessage(zerolog.TraceLevel, message, sessionID, true)
 
+	if !app.applyThrottle(message, sessionID) {
+		return nil
+	}
+
+	if app.isDropCopySession(sessionID) && isOrderEntry(msgType) {
+		refID, _ := message.Body.GetString(tag.ClOrdID)
+		app.sendBusinessMessageReject(sessionID, enum.MsgType(msgType), refID, enum.BusinessRejectReason_NOT_AUTHORIZED, "drop-copy session")
+		return nil
+	}
+
+	app.mux.Lock()
+	rule := app.rules.Match(message)
+	app.mux.Unlock()
+
+	if rule != nil {
+		return app.applyRule(rule, message, sessionID)
+	}
+
 	return app.router.Route(message, sessionID)
 }
 
 func (app *Acceptor) onNewOrderSingle(order *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	return app.newOrderSingle(order, sessionID, nil)
+}
+
+// newOrderSingle handles a new order, rule is the behaviour rule the order
+// matched if any.
+func (app *Acceptor) newOrderSingle(order *quickfix.Message, sessionID quickfix.SessionID, rule *rules.Rule) quickfix.MessageRejectError {
+	clOrdID, ferr := order.Body.GetString(tag.ClOrdID)
+	if ferr != nil {
+		return ferr
+	}
+
 	symbol, ferr := order.Body.GetString(tag.Symbol)
 	if ferr != nil {
 		return ferr
@@ -169,53 +448,271 @@ func (app *Acceptor) onNewOrderSingle(order *quickfix.Message, sessionID quickfi
 		return ferr
 	}
 
-	sideString, _ := dict.Search(dict.OrderSides, side)
-	typeString, _ := dict.Search(dict.OrderTypes, ordType)
+	orderQty := field.OrderQtyField{}
+	if ferr = order.Body.GetField(tag.OrderQty, &orderQty); ferr != nil {
+		return ferr
+	}
 
-	buf := bytes.NewBuffer([]byte{})
-	subj := NewOrderSingleNatsSubject{
-		Symbol: symbol,
-		Side:   string(sideString),
-		Type:   string(typeString),
+	price := field.PriceField{}
+	switch enum.OrdType(ordType) {
+	case enum.OrdType_MARKET, enum.OrdType_STOP_STOP_LOSS:
+	default:
+		if ferr = order.Body.GetField(tag.Price, &price); ferr != nil {
+			return ferr
+		}
 	}
 
-	err := app.NatsOrderSubject.Execute(buf, subj)
-	if err != nil {
-		return quickfix.NewMessageRejectError(err.Error(), int(tag.BusinessRejectReason), nil)
+	stopPx := field.StopPxField{}
+	switch enum.OrdType(ordType) {
+	case enum.OrdType_STOP_STOP_LOSS, enum.OrdType_STOP_LIMIT:
+		if !order.Body.Has(tag.StopPx) {
+			return quickfix.ConditionallyRequiredFieldMissing(tag.StopPx)
+		}
+		if ferr = order.Body.GetField(tag.StopPx, &stopPx); ferr != nil {
+			return ferr
+		}
+	}
+
+	// FIX.4.x gives the displayed quantity of icebergs in MaxFloor
+	displayQty := field.DisplayQtyField{}
+	if order.Body.Has(tag.DisplayQty) {
+		if ferr = order.Body.GetField(tag.DisplayQty, &displayQty); ferr != nil {
+			return ferr
+		}
+	} else if order.Body.Has(tag.MaxFloor) {
+		if ferr = order.Body.GetField(tag.MaxFloor, &displayQty); ferr != nil {
+			return ferr
+		}
+	}
+
+	timeInForce := field.TimeInForceField{FIXString: quickfix.FIXString(enum.TimeInForce_DAY)}
+	if order.Body.Has(tag.TimeInForce) {
+		if ferr = order.Body.GetField(tag.TimeInForce, &timeInForce); ferr != nil {
+			return ferr
+		}
+	}
+
+	o := &engine.Order{
+		SessionID:    sessionID,
+		OrderID:      uuid.NewString(),
+		ClOrdID:      clOrdID,
+		Symbol:       symbol,
+		Side:         enum.Side(side),
+		Type:         enum.OrdType(ordType),
+		TimeInForce:  timeInForce.Value(),
+		Price:        price.Value(),
+		Quantity:     orderQty.Value(),
+		Status:       enum.OrdStatus_NEW,
+		TransactTime: time.Now(),
+		StopPx:       stopPx.Value(),
+		DisplayQty:   displayQty.Value(),
+	}
+
+	if o.ExpireTime, ferr = app.orderExpireTime(order, o); ferr != nil {
+		return ferr
+	}
+
+	if rule != nil && rule.Action == rules.ActionReject {
+		o.Status = enum.OrdStatus_REJECTED
+		if err := app.sendOrderReject(*o, rule.GetOrdRejReason(), rule.GetText()); err != nil {
+			return quickfix.NewMessageRejectError(err.Error(), int(tag.BusinessRejectReason), nil)
+		}
+		return nil
 	}
 
-	err = app.natsConn.Publish(buf.String(), []byte(order.ToMessage().String()))
+	app.mux.Lock()
+	phase, err := app.checkPhase(o.Symbol)
+	app.mux.Unlock()
+
 	if err != nil {
+		o.Status = enum.OrdStatus_REJECTED
+		if err = app.sendOrderReject(*o, enum.OrdRejReason_EXCHANGE_CLOSED, err.Error()); err != nil {
+			return quickfix.NewMessageRejectError(err.Error(), int(tag.BusinessRejectReason), nil)
+		}
+		return nil
+	}
+
+	// Auctions only collect orders which can rest in the book until they
+	// uncross, what is left of market orders is canceled then
+	if phase.IsAuction() && o.HasImmediateTimeInForce() {
+		o.Status = enum.OrdStatus_REJECTED
+		if err = app.sendOrderReject(*o, enum.OrdRejReason_UNSUPPORTED_ORDER_CHARACTERISTIC, errors.AcceptorAuctionOrder.Error()); err != nil {
+			return quickfix.NewMessageRejectError(err.Error(), int(tag.BusinessRejectReason), nil)
+		}
+		return nil
+	}
+
+	// Open orders are counted and the order submitted under the same lock
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	if violation := app.checkRisk(o); violation != nil {
+		o.Status = enum.OrdStatus_REJECTED
+		if err := app.sendOrderReject(*o, violation.Reason, violation.Error()); err != nil {
+			return quickfix.NewMessageRejectError(err.Error(), int(tag.BusinessRejectReason), nil)
+		}
+		return nil
+	}
+
+	// The downstream OMS owns the order lifecycle
+	if app.natsBridge {
+		return app.forward(app.natsOrders, order, sessionID)
+	}
+
+	if err = app.dispatch(order, sessionID); err != nil {
 		return quickfix.NewMessageRejectError(err.Error(), int(tag.BusinessRejectReason), nil)
 	}
 
-	err = app.sendExecutionReport(order, enum.OrdStatus(enum.OrdStatus_NEW))
+	err = app.sendExecutionReport(*o, enum.ExecType_NEW, nil)
 	if err != nil {
 		return quickfix.NewMessageRejectError(err.Error(), int(tag.BusinessRejectReason), nil)
 	}
 
+	var fills []engine.Fill
+
+	// Orders collected by auctions and stop orders waiting for their trigger
+	// go straight to the engine
+	hold := phase.IsAuction() || o.IsArmed()
+
+	// Partially fill limit orders outside of the book before matching them
+	if rule != nil && rule.Action == rules.ActionPartialFill && !o.IsMarket() && !hold {
+		qty := o.Quantity.Mul(decimal.NewFromInt(int64(rule.FillPercent))).Div(decimal.NewFromInt(100)).Round(2)
+		if qty.IsPositive() {
+			fill := app.engine.Execute(o, o.Price, qty)
+			if err = app.sendExecutionReport(fill.Aggressor, enum.ExecType_TRADE, &fill); err != nil {
+				app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", o.OrderID)
+			}
+			fills = append(fills, fill)
+		}
+	}
+
+	// Fill what is left of the order outside of the book in auto fill mode,
+	// market orders which can not be priced go to the book
+	if app.fillMode == FillModeAuto && o.LeavesQty().IsPositive() && !hold {
+		if fill, ok := app.autoFill(o); ok {
+			if err = app.sendExecutionReport(fill.Aggressor, enum.ExecType_TRADE, &fill); err != nil {
+				app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", o.OrderID)
+			}
+			fills = append(fills, fill)
+		}
+	}
+
+	if o.LeavesQty().IsPositive() {
+		matched := app.engine.Submit(o)
+		app.sendFills(matched)
+		fills = append(fills, matched...)
+	}
+
+	// Market, IOC and FOK orders remainders are not kept in the book
+	if o.Status == enum.OrdStatus_CANCELED {
+		if err = app.sendExecutionReport(*o, enum.ExecType_CANCELED, nil); err != nil {
+			app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", o.OrderID)
+		}
+	}
+
+	app.publishMarketData(o.Symbol, fills)
+	app.sendTradeCaptureReports(fills)
+
+	app.persistOrder(*o)
+	for _, fill := range fills {
+		app.persistOrder(fill.Resting)
+	}
+
+	app.triggerStops(o.Symbol)
+
 	return nil
 }
 
-func (a *Acceptor) sendExecutionReport(order *quickfix.Message, status enum.OrdStatus) error {
-	message := quickfix.NewMessage()
-	header := fixt11.NewHeader(&message.Header)
+// sendBusinessMessageReject rejects an application message which is valid but
+// can not be processed.
+func (app *Acceptor) sendBusinessMessageReject(sessionID quickfix.SessionID, refMsgType enum.MsgType, refID string, reason enum.BusinessRejectReason, text string) {
+	message := newMessage(sessionID, enum.MsgType_BUSINESS_MESSAGE_REJECT)
+
+	message.Body.Set(field.NewRefMsgType(string(refMsgType)))
+	utils.QuickFixMessagePartSetString(&message.Body, refID, field.NewBusinessRejectRefID)
+	message.Body.Set(field.NewBusinessRejectReason(reason))
+	message.Body.Set(field.NewText(text))
+
+	if err := quickfix.SendToTarget(message, sessionID); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send business message reject for %s", refMsgType)
+	}
+}
 
-	header.SetField(tag.MsgType, field.NewMsgType(enum.MsgType_EXECUTION_REPORT))
-	utils.QuickFixMessagePartSetString(&header, utils.MustNot(order.Header.GetString(tag.SenderCompID)), field.NewTargetCompID)
-	utils.QuickFixMessagePartSetString(&header, utils.MustNot(order.Header.GetString(tag.SenderSubID)), field.NewTargetSubID)
-	utils.QuickFixMessagePartSetString(&header, utils.MustNot(order.Header.GetString(tag.TargetCompID)), field.NewSenderCompID)
-	utils.QuickFixMessagePartSetString(&header, utils.MustNot(order.Header.GetString(tag.TargetSubID)), field.NewSenderSubID)
+// sendFills sends a trade execution report to both sides of each fill and a
+// restated one when the resting iceberg order shows a new peak.
+func (app *Acceptor) sendFills(fills []engine.Fill) {
+	for _, fill := range fills {
+		fill := fill
+		if err := app.sendExecutionReport(fill.Resting, enum.ExecType_TRADE, &fill); err != nil {
+			app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", fill.Resting.OrderID)
+		}
+		if fill.Replenished {
+			message := newExecutionReport(fill.Resting, enum.ExecType_RESTATED, nil)
+			message.Body.Set(field.NewExecRestatementReason(enum.ExecRestatementReason_OTHER))
+			message.Body.Set(field.NewText("peak replenished"))
+			if err := quickfix.SendToTarget(message, fill.Resting.SessionID); err != nil {
+				app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", fill.Resting.OrderID)
+			}
+		}
+		if err := app.sendExecutionReport(fill.Aggressor, enum.ExecType_TRADE, &fill); err != nil {
+			app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", fill.Aggressor.OrderID)
+		}
+	}
+}
+
+// sendExecutionReport sends an execution report reflecting the given order
+// state to the session which owns the order. fill is only given for trades.
+func (app *Acceptor) sendExecutionReport(order engine.Order, execType enum.ExecType, fill *engine.Fill) error {
+	return quickfix.SendToTarget(newExecutionReport(order, execType, fill), order.SessionID)
+}
+
+// sendOrderReject sends a rejected execution report for an order which never
+// made it to the engine.
+func (app *Acceptor) sendOrderReject(order engine.Order, reason enum.OrdRejReason, text string) error {
+	message := newExecutionReport(order, enum.ExecType_REJECTED, nil)
+	message.Body.Set(field.NewOrdRejReason(reason))
+	message.Body.Set(field.NewText(text))
+
+	return quickfix.SendToTarget(message, order.SessionID)
+}
+
+func newExecutionReport(order engine.Order, execType enum.ExecType, fill *engine.Fill) *quickfix.Message {
+	message := newMessage(order.SessionID, enum.MsgType_EXECUTION_REPORT)
+
+	message.Body.Set(field.NewOrderID(order.OrderID))
+	message.Body.Set(field.NewExecID(uuid.NewString()))
+	message.Body.Set(field.NewExecType(execType))
+	message.Body.Set(field.NewOrdStatus(order.Status))
+	message.Body.Set(field.NewClOrdID(order.ClOrdID))
+	utils.QuickFixMessagePartSetString(&message.Body, order.OrigClOrdID, field.NewOrigClOrdID)
+	message.Body.Set(field.NewSymbol(order.Symbol))
+	message.Body.Set(field.NewSide(order.Side))
+	message.Body.Set(field.NewOrdType(order.Type))
+	message.Body.Set(field.NewTimeInForce(order.TimeInForce))
+	message.Body.Set(field.NewOrderQty(order.Quantity, 2))
+	message.Body.Set(field.NewLeavesQty(order.LeavesQty(), 2))
+	message.Body.Set(field.NewCumQty(order.CumQty, 2))
+	message.Body.Set(field.NewAvgPx(order.AvgPx, 2))
+	message.Body.Set(field.NewTransactTime(time.Now()))
+
+	if order.HasPrice() {
+		message.Body.Set(field.NewPrice(order.Price, 2))
+	}
+
+	if order.IsStop() {
+		message.Body.Set(field.NewStopPx(order.StopPx, 2))
+	}
+
+	if order.IsIceberg() {
+		message.Body.Set(field.NewDisplayQty(order.Visible(), 2))
+	}
+
+	if fill != nil {
+		message.Body.Set(field.NewLastPx(fill.Price, 2))
+		message.Body.Set(field.NewLastQty(fill.Quantity, 2))
+	}
 
-	utils.QuickFixMessagePartSetString(&message.Body, (utils.MustNot(order.Body.GetString(tag.ClOrdID))), field.NewOrderID)
-	utils.QuickFixMessagePartSetString(&message.Body, "0", field.NewExecID)
-	utils.QuickFixMessagePartSetString(&message.Body, enum.ExecType(status), field.NewExecType)
-	utils.QuickFixMessagePartSetString(&message.Body, status, field.NewOrdStatus)
-	utils.QuickFixMessagePartSetString(&message.Body, enum.Side(utils.MustNot(order.Body.GetString(tag.Side))), field.NewSide)
-	utils.QuickFixMessagePartSetString(&message.Body, utils.MustNot(order.Body.GetString(tag.ClOrdID)), field.NewClOrdID)
-	utils.QuickFixMessagePartSetDecimal(&message.Body, utils.MustNot(order.Body.GetString(tag.LeavesQty)), field.NewLeavesQty, 2)
-	utils.QuickFixMessagePartSetDecimal(&message.Body, utils.MustNot(order.Body.GetString(tag.CumQty)), field.NewCumQty, 2)
-	utils.QuickFixMessagePartSetDecimal(&message.Body, utils.MustNot(order.Body.GetString(tag.OrderQty)), field.NewOrderQty, 2)
+	setExecutionReportVersion(&message.Body, order.SessionID.BeginString)
 
-	return quickfix.Send(message)
+	return message
 }
@@ -0,0 +1,61 @@
+package application
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/quickfixgo/quickfix"
+)
+
+var (
+	metricDynamicSessions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "fix",
+			Subsystem: "acceptor",
+			Name:      "dynamic_sessions_total",
+			Help:      "Number of sessions created on the fly",
+		},
+		[]string{"session"},
+	)
+	metricSessionsLoggedOn = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "fix",
+			Subsystem: "acceptor",
+			Name:      "session_logged_on",
+			Help:      "Whether the session is logged on",
+		},
+		[]string{"session", "dynamic"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(metricDynamicSessions)
+	prometheus.MustRegister(metricSessionsLoggedOn)
+}
+
+// sessionSettings returns the settings of the session, dynamic sessions get
+// the dynamic settings they were created from.
+func (app *Acceptor) sessionSettings(sessionID quickfix.SessionID) (settings *quickfix.SessionSettings, dynamic bool) {
+	if app.Settings == nil {
+		return nil, false
+	}
+
+	if settings, ok := app.Settings.SessionSettings()[sessionID]; ok {
+		return settings, false
+	}
+
+	if app.DynamicSettings != nil {
+		return app.DynamicSettings, true
+	}
+
+	return app.Settings.GlobalSettings(), true
+}
+
+// boolSetting returns true if the setting of the session is set to Y.
+func (app *Acceptor) boolSetting(sessionID quickfix.SessionID, setting string) bool {
+	settings, _ := app.sessionSettings(sessionID)
+	if settings == nil || !settings.HasSetting(setting) {
+		return false
+	}
+
+	value, err := settings.BoolSetting(setting)
+	return err == nil && value
+}
@@ -0,0 +1,137 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/quickfixgo/enum"
+	"github.com/shopspring/decimal"
+
+	"sylr.dev/fix/pkg/acceptor/engine"
+	"sylr.dev/fix/pkg/acceptor/refdata"
+	"sylr.dev/fix/pkg/errors"
+)
+
+// StopTrigger is the reference price stop orders are triggered by.
+type StopTrigger string
+
+const (
+	// StopTriggerLast triggers stop orders on the last trade price.
+	StopTriggerLast StopTrigger = "last"
+	// StopTriggerMid triggers stop orders on the middle of the best bid and
+	// offer.
+	StopTriggerMid StopTrigger = "mid"
+	// StopTriggerExternal triggers stop orders on the prices posted through
+	// the admin API.
+	StopTriggerExternal StopTrigger = "external"
+)
+
+// stopPrice returns the price the stop orders of the symbol are compared to
+// or false if there is none yet. It must be called with app.mux held.
+func (app *Acceptor) stopPrice(symbol string) (decimal.Decimal, bool) {
+	switch app.stopTrigger {
+	case StopTriggerMid:
+		bids, asks := app.engine.Depth(symbol, 1)
+		if len(bids) == 0 || len(asks) == 0 {
+			return decimal.Zero, false
+		}
+		return bids[0].Price.Add(asks[0].Price).Div(decimal.NewFromInt(2)), true
+
+	case StopTriggerExternal:
+		price, ok := app.externalPrices[symbol]
+		return price, ok
+
+	default:
+		if trade := app.engine.LastTrade(symbol); trade != nil {
+			return trade.Price, true
+		}
+		return decimal.Zero, false
+	}
+}
+
+// triggerStops releases the stop orders of the symbol reached by its stop
+// price and matches them as market or limit orders, once they passed the risk
+// checks again. Their trades can move the price and trigger other stops. Stops
+// are only triggered while the symbol trades continuously. It must be called
+// with app.mux held.
+func (app *Acceptor) triggerStops(symbol string) {
+	if phase, ok := app.symbolPhases[symbol]; ok && phase != refdata.PhaseContinuous {
+		return
+	}
+
+	for {
+		price, ok := app.stopPrice(symbol)
+		if !ok {
+			return
+		}
+
+		triggered := app.engine.Trigger(symbol, price)
+		if len(triggered) == 0 {
+			return
+		}
+
+		for _, order := range triggered {
+			app.Logger.Info().Msgf("Stop order %s triggered at %s", order.OrderID, price)
+
+			if err := app.sendExecutionReport(*order, enum.ExecType_TRIGGERED_OR_ACTIVATED_BY_SYSTEM, nil); err != nil {
+				app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", order.OrderID)
+			}
+
+			// Triggered stops are valued at the reference or their limit price
+			if violation := app.checkRisk(order); violation != nil {
+				app.Logger.Warn().Err(violation).Msgf("Canceling triggered stop order %s", order.OrderID)
+				for _, canceled := range app.engine.CancelAll(func(o *engine.Order) bool { return o.OrderID == order.OrderID }) {
+					if err := app.sendExecutionReport(canceled, enum.ExecType_CANCELED, nil); err != nil {
+						app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", order.OrderID)
+					}
+					app.persistOrder(canceled)
+				}
+				continue
+			}
+
+			fills := app.engine.Submit(order)
+			app.sendFills(fills)
+
+			if order.Status == enum.OrdStatus_CANCELED {
+				if err := app.sendExecutionReport(*order, enum.ExecType_CANCELED, nil); err != nil {
+					app.Logger.Error().Err(err).Msgf("Unable to send execution report for order %s", order.OrderID)
+				}
+			}
+
+			app.publishMarketData(symbol, fills)
+			app.sendTradeCaptureReports(fills)
+
+			app.persistOrder(*order)
+			for _, fill := range fills {
+				app.persistOrder(fill.Resting)
+			}
+		}
+	}
+}
+
+// TriggerRestoredStops triggers the restored stop orders which were reached
+// while the acceptor was down, it is called once the sessions are created.
+func (app *Acceptor) TriggerRestoredStops() {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	for symbol := range app.restoredStops {
+		app.triggerStops(symbol)
+	}
+	app.restoredStops = nil
+}
+
+// setExternalPrice records the reference price of the symbol used to trigger
+// stop orders with the external trigger.
+func (app *Acceptor) setExternalPrice(symbol string, price decimal.Decimal) error {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	if app.stopTrigger != StopTriggerExternal {
+		return fmt.Errorf("%w: %s", errors.AcceptorStopTriggerNotExternal, app.stopTrigger)
+	}
+
+	app.externalPrices[symbol] = price
+	app.triggerStops(symbol)
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package application
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/config"
+)
+
+// ThrottleAction is what the acceptor does with messages exceeding the rate.
+type ThrottleAction string
+
+const (
+	// ThrottleActionReject answers messages exceeding the rate with a
+	// BusinessMessageReject.
+	ThrottleActionReject ThrottleAction = "reject"
+	// ThrottleActionQueue holds messages back until the rate allows them,
+	// messages exceeding twice the burst are rejected.
+	ThrottleActionQueue ThrottleAction = "queue"
+	// ThrottleActionLogout logs the session out.
+	ThrottleActionLogout ThrottleAction = "logout"
+)
+
+const throttleText = "throttle limit exceeded"
+
+var metricThrottleHits = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "fix",
+		Subsystem: "acceptor",
+		Name:      "throttle_hits_total",
+		Help:      "Number of application messages exceeding the session throttle",
+	},
+	[]string{"session", "action"},
+)
+
+func init() {
+	prometheus.MustRegister(metricThrottleHits)
+}
+
+// throttle is a token bucket refilled at rate tokens per second holding up to
+// burst tokens.
+type throttle struct {
+	rate   float64
+	burst  float64
+	action ThrottleAction
+	tokens float64
+	last   time.Time
+	mux    sync.Mutex
+}
+
+// newThrottle reads the throttle of the session from its settings, it returns
+// nil if the session is not throttled.
+func newThrottle(settings *quickfix.SessionSettings) (*throttle, error) {
+	if settings == nil || !settings.HasSetting(config.ThrottleRateSetting) {
+		return nil, nil
+	}
+
+	raw, err := settings.Setting(config.ThrottleRateSetting)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	burst := 1
+	if settings.HasSetting(config.ThrottleBurstSetting) {
+		if burst, err = settings.IntSetting(config.ThrottleBurstSetting); err != nil {
+			return nil, err
+		}
+	}
+
+	action := ThrottleActionReject
+	if settings.HasSetting(config.ThrottleActionSetting) {
+		a, err := settings.Setting(config.ThrottleActionSetting)
+		if err != nil {
+			return nil, err
+		}
+		action = ThrottleAction(a)
+	}
+
+	b := math.Max(float64(burst), 1)
+
+	return &throttle{rate: rate, burst: b, action: action, tokens: b, last: time.Now()}, nil
+}
+
+// take consumes a token, ok is false if there is none. Throttles queueing
+// messages reserve the next token instead and return how long to wait for it,
+// they go into debt by up to one burst so that the wait is bounded.
+func (t *throttle) take() (wait time.Duration, ok bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	now := time.Now()
+	t.tokens = math.Min(t.burst, t.tokens+now.Sub(t.last).Seconds()*t.rate)
+	t.last = now
+
+	if t.tokens >= 1 {
+		t.tokens--
+		return 0, true
+	}
+
+	if t.action != ThrottleActionQueue || t.tokens-1 < -t.burst {
+		return 0, false
+	}
+
+	wait = time.Duration((1 - t.tokens) / t.rate * float64(time.Second))
+	t.tokens--
+
+	return wait, true
+}
+
+func (app *Acceptor) registerThrottle(sessionID quickfix.SessionID) {
+	settings, _ := app.sessionSettings(sessionID)
+
+	t, err := newThrottle(settings)
+	if err != nil {
+		app.Logger.Error().Err(err).Msgf("Invalid throttle for session %s", sessionID)
+		return
+	} else if t == nil {
+		return
+	}
+
+	app.mux.Lock()
+	app.throttles[sessionID] = t
+	app.mux.Unlock()
+}
+
+// applyThrottle applies the session throttle to the message. It returns false
+// if the message must not be processed.
+func (app *Acceptor) applyThrottle(msg *quickfix.Message, sessionID quickfix.SessionID) bool {
+	app.mux.Lock()
+	t, ok := app.throttles[sessionID]
+	app.mux.Unlock()
+
+	if !ok {
+		return true
+	}
+
+	wait, ok := t.take()
+	if ok && wait == 0 {
+		return true
+	}
+
+	action := t.action
+	if action == ThrottleActionQueue && !ok {
+		action = ThrottleActionReject
+	}
+
+	metricThrottleHits.WithLabelValues(sessionID.String(), string(action)).Inc()
+
+	switch action {
+	case ThrottleActionQueue:
+		time.Sleep(wait)
+		return true
+	case ThrottleActionLogout:
+		app.Logger.Warn().Msgf("Logging out session %s: %s", sessionID, throttleText)
+		if err := app.logout(sessionID, throttleText); err != nil {
+			app.Logger.Error().Err(err).Msgf("Unable to log out session %s", sessionID)
+		}
+	default:
+		msgType, _ := msg.MsgType()
+		refID, _ := msg.Body.GetString(tag.ClOrdID)
+		app.sendBusinessMessageReject(sessionID, enum.MsgType(msgType), refID, enum.BusinessRejectReason_OTHER, throttleText)
+	}
+
+	return false
+}
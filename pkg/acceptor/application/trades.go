@@ -0,0 +1,339 @@
+package application
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/acceptor/engine"
+	"sylr.dev/fix/pkg/errors"
+)
+
+// FillMode is how the acceptor executes the orders it accepts.
+type FillMode string
+
+const (
+	// FillModeBook matches orders against the order book of their symbol.
+	FillModeBook FillMode = "book"
+	// FillModeAuto fills accepted orders in full outside of the book, at their
+	// limit price or at the reference price of their symbol for market orders.
+	FillModeAuto FillMode = "auto"
+)
+
+// registerPostTradeSession remembers the session if it is a post-trade one.
+func (app *Acceptor) registerPostTradeSession(sessionID quickfix.SessionID) {
+	if !app.boolSetting(sessionID, config.PostTradeSetting) {
+		return
+	}
+
+	app.postTradeMux.Lock()
+	app.postTradeSessions[sessionID] = false
+	app.postTradeMux.Unlock()
+
+	app.Logger.Info().Msgf("Post-trade session: %s", sessionID)
+}
+
+// setPostTradeLoggedOn records the logon state of post-trade sessions, trade
+// capture reports are only sent to logged on sessions.
+func (app *Acceptor) setPostTradeLoggedOn(sessionID quickfix.SessionID, loggedOn bool) {
+	app.postTradeMux.Lock()
+	defer app.postTradeMux.Unlock()
+
+	if _, ok := app.postTradeSessions[sessionID]; ok {
+		app.postTradeSessions[sessionID] = loggedOn
+	}
+}
+
+func (app *Acceptor) isPostTradeSession(sessionID quickfix.SessionID) bool {
+	app.postTradeMux.RLock()
+	defer app.postTradeMux.RUnlock()
+
+	_, ok := app.postTradeSessions[sessionID]
+	return ok
+}
+
+// referencePrice returns the last trade price of the symbol or, until it
+// trades, its previous close price. app.mux must be held.
+func (app *Acceptor) referencePrice(symbol string) decimal.Decimal {
+	if trade := app.engine.LastTrade(symbol); trade != nil {
+		return trade.Price
+	}
+
+	if instrument := app.refData.Instrument(symbol); instrument != nil {
+		return instrument.PrevClosePx
+	}
+
+	return decimal.Zero
+}
+
+// autoFill executes what is left of the order outside of the book. Market
+// orders can not be filled if their symbol has no reference price.
+func (app *Acceptor) autoFill(order *engine.Order) (engine.Fill, bool) {
+	price := order.Price
+	if order.IsMarket() {
+		price = app.referencePrice(order.Symbol)
+	}
+
+	if !price.IsPositive() {
+		return engine.Fill{}, false
+	}
+
+	return app.engine.Execute(order, price, order.LeavesQty()), true
+}
+
+// sendTradeCaptureReports reports each fill to the sessions of the orders
+// involved, with their side only, and to the post-trade sessions with both
+// sides. FIX.4.2 sessions are left out as they have no trade capture reports.
+func (app *Acceptor) sendTradeCaptureReports(fills []engine.Fill) {
+	if len(fills) == 0 {
+		return
+	}
+
+	app.postTradeMux.RLock()
+	targets := make([]quickfix.SessionID, 0, len(app.postTradeSessions))
+	for target, loggedOn := range app.postTradeSessions {
+		if loggedOn {
+			targets = append(targets, target)
+		}
+	}
+	app.postTradeMux.RUnlock()
+
+	for _, fill := range fills {
+		sides := fillSides(fill)
+
+		for _, side := range sides {
+			app.sendTradeCaptureReport(side.SessionID, newTradeCaptureReport(side.SessionID, fill, side))
+		}
+
+		for _, target := range targets {
+			app.sendTradeCaptureReport(target, newTradeCaptureReport(target, fill, sides...))
+		}
+	}
+}
+
+func (app *Acceptor) sendTradeCaptureReport(sessionID quickfix.SessionID, report *quickfix.Message) {
+	if sessionID.BeginString == quickfix.BeginStringFIX42 {
+		return
+	}
+
+	if err := quickfix.SendToTarget(report, sessionID); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send trade capture report to %s", sessionID)
+	}
+}
+
+// onTradeCaptureReportRequest replays the trades of the day the session took
+// part in, or all of them for post-trade sessions. Trades can be filtered by
+// symbol, TradeID and time range. The replay ends with an acknowledgement.
+func (app *Acceptor) onTradeCaptureReportRequest(msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	tradeRequestID, ferr := msg.Body.GetString(tag.TradeRequestID)
+	if ferr != nil {
+		return ferr
+	}
+
+	requestType := field.TradeRequestTypeField{}
+	if ferr = msg.Body.GetField(tag.TradeRequestType, &requestType); ferr != nil {
+		return ferr
+	}
+
+	if requestType.Value() != enum.TradeRequestType_ALL_TRADES {
+		app.sendTradeCaptureReportRequestAck(sessionID, tradeRequestID, requestType.Value(), enum.TradeRequestStatus_REJECTED, enum.TradeRequestResult_TRADE_REQUEST_TYPE_NOT_SUPPORTED, 0, errors.AcceptorTradeRequestNotSupported)
+		return nil
+	}
+
+	symbol, _ := msg.Body.GetString(tag.Symbol)
+	tradeID, _ := msg.Body.GetString(tag.TradeID)
+
+	from, to, ferr := tradeRequestDates(msg)
+	if ferr != nil {
+		return ferr
+	}
+
+	y, m, d := time.Now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	postTrade := app.isPostTradeSession(sessionID)
+
+	trades := app.engine.Trades(func(fill *engine.Fill) bool {
+		switch {
+		case fill.Time.Before(today),
+			!from.IsZero() && fill.Time.Before(from),
+			!to.IsZero() && fill.Time.After(to),
+			len(symbol) > 0 && fill.Symbol != symbol,
+			len(tradeID) > 0 && fill.TradeID != tradeID,
+			!postTrade && fill.Aggressor.SessionID != sessionID && fill.Resting.SessionID != sessionID:
+			return false
+		}
+		return true
+	})
+
+	for i, trade := range trades {
+		sides := fillSides(trade)
+		if !postTrade {
+			sides = sessionSides(sides, sessionID)
+		}
+
+		report := newTradeCaptureReport(sessionID, trade, sides...)
+		report.Body.Set(field.NewTradeRequestID(tradeRequestID))
+		report.Body.Set(field.NewPreviouslyReported(true))
+		report.Body.Set(field.NewTotNumTradeReports(len(trades)))
+		report.Body.Set(field.NewLastRptRequested(i == len(trades)-1))
+
+		if err := quickfix.SendToTarget(report, sessionID); err != nil {
+			app.Logger.Error().Err(err).Msgf("Unable to send trade capture report for %s", tradeRequestID)
+		}
+	}
+
+	app.sendTradeCaptureReportRequestAck(sessionID, tradeRequestID, requestType.Value(), enum.TradeRequestStatus_COMPLETED, enum.TradeRequestResult_SUCCESSFUL, len(trades), nil)
+
+	return nil
+}
+
+func (app *Acceptor) sendTradeCaptureReportRequestAck(sessionID quickfix.SessionID, tradeRequestID string, requestType enum.TradeRequestType, status enum.TradeRequestStatus, result enum.TradeRequestResult, reports int, cause error) {
+	message := newMessage(sessionID, enum.MsgType_TRADE_CAPTURE_REPORT_REQUEST_ACK)
+
+	message.Body.Set(field.NewTradeRequestID(tradeRequestID))
+	message.Body.Set(field.NewTradeRequestType(requestType))
+	message.Body.Set(field.NewTradeRequestStatus(status))
+	message.Body.Set(field.NewTradeRequestResult(result))
+	message.Body.Set(field.NewTotNumTradeReports(reports))
+
+	if cause != nil {
+		message.Body.Set(field.NewText(cause.Error()))
+	}
+
+	if err := quickfix.SendToTarget(message, sessionID); err != nil {
+		app.Logger.Error().Err(err).Msgf("Unable to send trade capture report request ack for %s", tradeRequestID)
+	}
+}
+
+// tradeRequestDates returns the time range of a trade capture report request,
+// the first date is the start of the range and the second one its end. Trade
+// dates stand for the whole day.
+func tradeRequestDates(msg *quickfix.Message) (from, to time.Time, ferr quickfix.MessageRejectError) {
+	if !msg.Body.Has(tag.NoDates) {
+		return
+	}
+
+	dates := quickfix.NewRepeatingGroup(
+		tag.NoDates,
+		quickfix.GroupTemplate{
+			quickfix.GroupElement(tag.TradeDate),
+			quickfix.GroupElement(tag.TransactTime),
+		},
+	)
+	if ferr = msg.Body.GetGroup(dates); ferr != nil {
+		return
+	}
+
+	bounds := make([]time.Time, 0, 2)
+	for i := 0; i < dates.Len() && i < 2; i++ {
+		entry := dates.Get(i)
+
+		if entry.Has(tag.TransactTime) {
+			t, ferr := entry.GetTime(tag.TransactTime)
+			if ferr != nil {
+				return from, to, ferr
+			}
+			bounds = append(bounds, t)
+			continue
+		}
+
+		tradeDate, ferr := entry.GetString(tag.TradeDate)
+		if ferr != nil {
+			return from, to, ferr
+		}
+		day, err := time.ParseInLocation("20060102", tradeDate, time.Local)
+		if err != nil {
+			return from, to, quickfix.IncorrectDataFormatForValue(tag.TradeDate)
+		}
+		if i == 1 {
+			day = day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		bounds = append(bounds, day)
+	}
+
+	if len(bounds) > 0 {
+		from = bounds[0]
+	}
+	if len(bounds) > 1 {
+		to = bounds[1]
+	}
+
+	return
+}
+
+// fillSides returns the orders involved in the fill, fills executed outside of
+// the book only have an aggressor.
+func fillSides(fill engine.Fill) []engine.Order {
+	if len(fill.Resting.OrderID) == 0 {
+		return []engine.Order{fill.Aggressor}
+	}
+
+	return []engine.Order{fill.Aggressor, fill.Resting}
+}
+
+func sessionSides(sides []engine.Order, sessionID quickfix.SessionID) []engine.Order {
+	var own []engine.Order
+	for _, side := range sides {
+		if side.SessionID == sessionID {
+			own = append(own, side)
+		}
+	}
+
+	return own
+}
+
+func newTradeCaptureReport(sessionID quickfix.SessionID, fill engine.Fill, sides ...engine.Order) *quickfix.Message {
+	message := newMessage(sessionID, enum.MsgType_TRADE_CAPTURE_REPORT)
+
+	message.Body.Set(field.NewTradeReportID(uuid.NewString()))
+	message.Body.Set(field.NewTradeID(fill.TradeID))
+	message.Body.Set(field.NewTradeReportType(enum.TradeReportType_SUBMIT))
+	message.Body.Set(field.NewPreviouslyReported(false))
+	message.Body.Set(field.NewSymbol(fill.Symbol))
+	message.Body.Set(field.NewLastQty(fill.Quantity, 2))
+	message.Body.Set(field.NewLastPx(fill.Price, 2))
+	message.Body.Set(field.NewTradeDate(fill.Time.Format("20060102")))
+	message.Body.Set(field.NewTransactTime(fill.Time))
+
+	parties := quickfix.GroupTemplate{
+		quickfix.GroupElement(tag.PartyID),
+		quickfix.GroupElement(tag.PartyIDSource),
+		quickfix.GroupElement(tag.PartyRole),
+	}
+
+	group := quickfix.NewRepeatingGroup(
+		tag.NoSides,
+		quickfix.GroupTemplate{
+			quickfix.GroupElement(tag.Side),
+			quickfix.GroupElement(tag.OrderID),
+			quickfix.GroupElement(tag.ClOrdID),
+			quickfix.NewRepeatingGroup(tag.NoPartyIDs, parties),
+		},
+	)
+
+	for _, side := range sides {
+		entry := group.Add()
+		entry.Set(field.NewSide(side.Side))
+		entry.Set(field.NewOrderID(side.OrderID))
+		entry.Set(field.NewClOrdID(side.ClOrdID))
+
+		// The counterparty which entered the order
+		party := quickfix.NewRepeatingGroup(tag.NoPartyIDs, parties)
+		p := party.Add()
+		p.Set(field.NewPartyID(side.SessionID.TargetCompID))
+		p.Set(field.NewPartyIDSource(enum.PartyIDSource_PROPRIETARY))
+		p.Set(field.NewPartyRole(enum.PartyRole_ENTERING_FIRM))
+		entry.SetGroup(party)
+	}
+
+	message.Body.SetGroup(group)
+
+	return message
+}
@@ -0,0 +1,132 @@
+package application
+
+import (
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/tag"
+)
+
+// Application versions served by the acceptor. FIXT.1.1 sessions are routed
+// on their ApplVerID, FIX.4.x sessions on their BeginString.
+var acceptorVersions = []string{
+	quickfix.BeginStringFIX42,
+	quickfix.BeginStringFIX44,
+	quickfix.ApplVerIDFIX50SP2,
+}
+
+// Dictionaries are the data dictionaries of a FIX version, FIX.4.x sessions
+// use the same dictionary for both.
+type Dictionaries struct {
+	Transport *datadictionary.DataDictionary
+	App       *datadictionary.DataDictionary
+}
+
+// dictionaries returns the data dictionaries of the session BeginString,
+// falling back on the ones of the application.
+func (app *Acceptor) dictionaries(sessionID quickfix.SessionID) (*datadictionary.DataDictionary, *datadictionary.DataDictionary) {
+	if d, ok := app.Dictionaries[sessionID.BeginString]; ok {
+		return d.Transport, d.App
+	}
+
+	return app.TransportDataDictionary, app.AppDataDictionary
+}
+
+// newMessage returns a message of the given type for the session, the rest of
+// the header is set by the session when the message is sent.
+func newMessage(sessionID quickfix.SessionID, msgType enum.MsgType) *quickfix.Message {
+	message := quickfix.NewMessage()
+	message.Header.Set(field.NewBeginString(sessionID.BeginString))
+	message.Header.Set(field.NewMsgType(msgType))
+
+	return message
+}
+
+// setExecutionReportVersion adapts the body of an execution report to the FIX
+// version of the session it is sent to. FIX.4.2 has no Trade ExecType, fills
+// are reported as partial fills or fills, and requires ExecTransType. FIX.4.x
+// has no Triggered ExecType, triggered stops are restated, and gives the
+// displayed quantity of icebergs in MaxFloor rather than DisplayQty.
+func setExecutionReportVersion(body *quickfix.Body, beginString string) {
+	execType, err := body.GetString(tag.ExecType)
+	if err != nil {
+		return
+	}
+
+	switch beginString {
+	case quickfix.BeginStringFIX42:
+		if enum.ExecType(execType) == enum.ExecType_TRADE {
+			ordStatus, _ := body.GetString(tag.OrdStatus)
+			if enum.OrdStatus(ordStatus) == enum.OrdStatus_FILLED {
+				body.Set(field.NewExecType(enum.ExecType_FILL))
+			} else {
+				body.Set(field.NewExecType(enum.ExecType_PARTIAL_FILL))
+			}
+		}
+		if !body.Has(tag.ExecTransType) {
+			body.Set(field.NewExecTransType(enum.ExecTransType_NEW))
+		}
+	default:
+		switch enum.ExecType(execType) {
+		case enum.ExecType_PARTIAL_FILL, enum.ExecType_FILL:
+			body.Set(field.NewExecType(enum.ExecType_TRADE))
+		}
+	}
+
+	if beginString == quickfix.BeginStringFIXT11 {
+		moveField(body, tag.MaxFloor, tag.DisplayQty)
+		return
+	}
+
+	if enum.ExecType(execType) == enum.ExecType_TRIGGERED_OR_ACTIVATED_BY_SYSTEM {
+		body.Set(field.NewExecType(enum.ExecType_RESTATED))
+	}
+	moveField(body, tag.DisplayQty, tag.MaxFloor)
+}
+
+// setMarketDataVersion adapts the body of a market data message and its entries
+// to the FIX version of the session it is sent to, FIX.4.x has neither
+// LastUpdateTime nor TradeID. It must be called before the entries are set.
+func setMarketDataVersion(body *quickfix.Body, entries *quickfix.RepeatingGroup, beginString string) {
+	if beginString == quickfix.BeginStringFIXT11 {
+		return
+	}
+
+	removeFields(&body.FieldMap, tag.LastUpdateTime)
+	for i := 0; i < entries.Len(); i++ {
+		removeFields(&entries.Get(i).FieldMap, tag.TradeID)
+	}
+}
+
+// moveField moves the value of a field of a body holding no repeating group to
+// another tag.
+func moveField(body *quickfix.Body, from, to quickfix.Tag) {
+	value, err := body.GetBytes(from)
+	if err != nil {
+		return
+	}
+
+	removeFields(&body.FieldMap, from)
+	body.SetBytes(to, value)
+}
+
+// removeFields removes the given tags from a field map holding no repeating
+// group.
+func removeFields(fieldMap *quickfix.FieldMap, tags ...quickfix.Tag) {
+	values := make(map[quickfix.Tag][]byte)
+	for _, t := range fieldMap.Tags() {
+		if value, err := fieldMap.GetBytes(t); err == nil {
+			values[t] = value
+		}
+	}
+
+	for _, t := range tags {
+		delete(values, t)
+	}
+
+	fieldMap.Clear()
+	for t, value := range values {
+		fieldMap.SetBytes(t, value)
+	}
+}
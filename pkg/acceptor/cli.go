@@ -64,7 +64,7 @@ func ValidateOptions(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	_, err = context.GetAcceptor()
+	acceptor, err := context.GetAcceptor()
 	if err != nil {
 		return err
 	}
@@ -73,7 +73,8 @@ func ValidateOptions(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	} else {
-		if len(sessions) == 0 {
+		// Acceptors with dynamic sessions can run without any listed session
+		if len(sessions) == 0 && acceptor.DynamicSessions == nil {
 			return errors.ConfigContextNoSession
 		}
 	}
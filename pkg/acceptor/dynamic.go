@@ -0,0 +1,125 @@
+package acceptor
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+
+	"github.com/quickfixgo/quickfix"
+	qconfig "github.com/quickfixgo/quickfix/config"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/errors"
+)
+
+// The listener session only makes quickfix listen on the acceptor port when the
+// context lists no session, no counterparty can log on it.
+const (
+	listenerSenderCompID = "LISTENER"
+	listenerTargetCompID = "NONE"
+)
+
+// newDynamicAcceptorSettings returns a copy of the settings for quickfix to run
+// an acceptor with dynamic sessions, its global settings are replaced by the
+// dynamic ones once the static sessions are created. A listener session is
+// added when there is no static session as quickfix only listens on the ports
+// of the sessions it knows.
+func newDynamicAcceptorSettings(settings *quickfix.Settings) (*quickfix.Settings, *quickfix.SessionID, error) {
+	acceptorSettings := quickfix.NewSettings()
+	*acceptorSettings.GlobalSettings() = *settings.GlobalSettings()
+
+	sessions := settings.SessionSettings()
+	for _, sessionSettings := range sessions {
+		if _, err := acceptorSettings.AddSession(sessionSettings); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(sessions) > 0 {
+		return acceptorSettings, nil, nil
+	}
+
+	sessionSettings := quickfix.NewSessionSettings()
+	sessionSettings.Set(qconfig.BeginString, quickfix.BeginStringFIX44)
+	sessionSettings.Set(qconfig.SenderCompID, listenerSenderCompID)
+	sessionSettings.Set(qconfig.TargetCompID, listenerTargetCompID)
+
+	listener, err := acceptorSettings.AddSession(sessionSettings)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return acceptorSettings, &listener, nil
+}
+
+// listenerApplication hides the listener session from the application.
+type listenerApplication struct {
+	quickfix.Application
+	listener quickfix.SessionID
+}
+
+func (app listenerApplication) OnCreate(sessionID quickfix.SessionID) {
+	if sessionID != app.listener {
+		app.Application.OnCreate(sessionID)
+	}
+}
+
+// CompIDValidator restricts the CompIDs of dynamic sessions, sessions listed in
+// the settings are always accepted and the listener session never is.
+type CompIDValidator struct {
+	settings     *quickfix.Settings
+	listener     *quickfix.SessionID
+	senderCompID *regexp.Regexp
+	targetCompID *regexp.Regexp
+}
+
+// NewCompIDValidator returns a validator using the CompID patterns found in the
+// global settings, or nil if there is none and no listener session.
+func NewCompIDValidator(settings *quickfix.Settings, listener *quickfix.SessionID) (*CompIDValidator, error) {
+	v := CompIDValidator{settings: settings, listener: listener}
+	global := settings.GlobalSettings()
+
+	for setting, re := range map[string]**regexp.Regexp{
+		config.DynamicSenderCompIDSetting: &v.senderCompID,
+		config.DynamicTargetCompIDSetting: &v.targetCompID,
+	} {
+		if !global.HasSetting(setting) {
+			continue
+		}
+
+		pattern, err := global.Setting(setting)
+		if err != nil {
+			return nil, err
+		}
+
+		if *re, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("%w: %s", errors.ConfigInvalidCompIDPattern, err)
+		}
+	}
+
+	if v.senderCompID == nil && v.targetCompID == nil && v.listener == nil {
+		return nil, nil
+	}
+
+	return &v, nil
+}
+
+func (v *CompIDValidator) Validate(conn net.Conn, sessionID quickfix.SessionID) error {
+	if v.listener != nil && sessionID == *v.listener {
+		return fmt.Errorf("%w: %s", errors.AcceptorCompIDNotAllowed, sessionID.SenderCompID)
+	}
+
+	if _, ok := v.settings.SessionSettings()[sessionID]; ok {
+		return nil
+	}
+
+	if v.senderCompID != nil && !v.senderCompID.MatchString(sessionID.SenderCompID) {
+		return fmt.Errorf("%w: %s", errors.AcceptorCompIDNotAllowed, sessionID.SenderCompID)
+	}
+
+	if v.targetCompID != nil && !v.targetCompID.MatchString(sessionID.TargetCompID) {
+		return fmt.Errorf("%w: %s", errors.AcceptorCompIDNotAllowed, sessionID.TargetCompID)
+	}
+
+	return nil
+}
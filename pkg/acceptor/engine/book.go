@@ -0,0 +1,374 @@
+package engine
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/quickfixgo/enum"
+)
+
+// Fill is a trade between an incoming order and a resting order. Aggressor and
+// Resting are snapshots of both orders right after the trade. Replenished is
+// true if the trade exhausted the peak of the resting iceberg order and a new
+// one was shown.
+type Fill struct {
+	TradeID     string
+	Symbol      string
+	Price       decimal.Decimal
+	Quantity    decimal.Decimal
+	Time        time.Time
+	Aggressor   Order
+	Resting     Order
+	Replenished bool
+}
+
+// Level is the aggregated quantity resting at a given price.
+type Level struct {
+	Price  decimal.Decimal
+	Size   decimal.Decimal
+	Orders int
+}
+
+// Book is a price-time priority limit order book for a single symbol. During
+// auctions orders, market ones included, rest without matching until the book
+// is uncrossed.
+type Book struct {
+	Symbol    string
+	Bids      []*Order
+	Asks      []*Order
+	LastTrade *Fill
+
+	auction bool
+}
+
+func NewBook(symbol string) *Book {
+	return &Book{Symbol: symbol}
+}
+
+// BestBid returns the best resting buy order or nil if there is none.
+func (b *Book) BestBid() *Order {
+	if len(b.Bids) == 0 {
+		return nil
+	}
+	return b.Bids[0]
+}
+
+// BestAsk returns the best resting sell order or nil if there is none.
+func (b *Book) BestAsk() *Order {
+	if len(b.Asks) == 0 {
+		return nil
+	}
+	return b.Asks[0]
+}
+
+// match executes the order against the opposite side of the book as long as
+// prices cross. Trades happen at the resting order price. Resting iceberg
+// orders only trade their peak at once, they lose their time priority when a
+// new peak is shown.
+func (b *Book) match(order *Order, now time.Time) []Fill {
+	var fills []Fill
+
+	opposite := &b.Asks
+	if !order.IsBuy() {
+		opposite = &b.Bids
+	}
+
+	for len(*opposite) > 0 && order.LeavesQty().IsPositive() {
+		resting := (*opposite)[0]
+		if !order.crosses(resting) {
+			break
+		}
+
+		qty := decimal.Min(order.LeavesQty(), resting.Visible())
+		px := resting.Price
+
+		order.fill(px, qty)
+		resting.fill(px, qty)
+		replenished := resting.consume(qty)
+
+		fills = append(fills, Fill{
+			TradeID:     uuid.NewString(),
+			Symbol:      b.Symbol,
+			Price:       px,
+			Quantity:    qty,
+			Time:        now,
+			Aggressor:   *order,
+			Resting:     *resting,
+			Replenished: replenished,
+		})
+
+		if resting.IsFinal() {
+			*opposite = (*opposite)[1:]
+		} else if replenished {
+			*opposite = (*opposite)[1:]
+			b.insert(resting)
+		}
+	}
+
+	if len(fills) > 0 {
+		b.LastTrade = &fills[len(fills)-1]
+	}
+
+	return fills
+}
+
+// equilibrium returns the price at which the book uncrosses: the one executing
+// the largest quantity, then leaving the smallest imbalance, then the closest
+// to the last trade, then the lowest. Market orders execute at any price, the
+// last trade price is the only one at which they can trade with each other.
+// Size is the quantity executed at this price, the book does not cross if
+// there is none.
+func (b *Book) equilibrium() (Level, bool) {
+	var prices []decimal.Decimal
+	for _, side := range [][]*Order{b.Bids, b.Asks} {
+		for _, order := range side {
+			if !order.IsMarket() {
+				prices = append(prices, order.Price)
+			}
+		}
+	}
+
+	best := b.bestLevel(prices)
+	if !best.Size.IsPositive() && b.LastTrade != nil {
+		best = b.bestLevel([]decimal.Decimal{b.LastTrade.Price})
+	}
+
+	return best, best.Size.IsPositive()
+}
+
+// bestLevel returns the candidate price the book uncrosses at along with the
+// quantity executed at this price.
+func (b *Book) bestLevel(prices []decimal.Decimal) Level {
+	var best Level
+	var bestImbalance decimal.Decimal
+
+	for _, px := range prices {
+		bought, sold := decimal.Zero, decimal.Zero
+		for _, bid := range b.Bids {
+			if bid.executesAt(px) {
+				bought = bought.Add(bid.LeavesQty())
+			}
+		}
+		for _, ask := range b.Asks {
+			if ask.executesAt(px) {
+				sold = sold.Add(ask.LeavesQty())
+			}
+		}
+
+		size := decimal.Min(bought, sold)
+		imbalance := bought.Sub(sold).Abs()
+
+		if !size.IsPositive() || size.LessThan(best.Size) {
+			continue
+		}
+
+		if size.Equal(best.Size) {
+			if imbalance.GreaterThan(bestImbalance) {
+				continue
+			}
+			if imbalance.Equal(bestImbalance) && !b.closer(px, best.Price) {
+				continue
+			}
+		}
+
+		best = Level{Price: px, Size: size}
+		bestImbalance = imbalance
+	}
+
+	return best
+}
+
+// closer returns true if a is closer to the last trade price than other or,
+// if they are as close, lower than other.
+func (b *Book) closer(a, other decimal.Decimal) bool {
+	if b.LastTrade != nil {
+		da := a.Sub(b.LastTrade.Price).Abs()
+		db := other.Sub(b.LastTrade.Price).Abs()
+		if !da.Equal(db) {
+			return da.LessThan(db)
+		}
+	}
+
+	return a.LessThan(other)
+}
+
+// uncross executes the crossing orders of the book at the equilibrium price in
+// price-time priority. The most recent order of each pair is the aggressor.
+// Iceberg orders trade their hidden quantity as well, those which exhausted
+// their peak show a new one and lose their time priority once the book is
+// uncrossed.
+func (b *Book) uncross(now time.Time) []Fill {
+	equilibrium, ok := b.equilibrium()
+	if !ok {
+		return nil
+	}
+
+	var fills []Fill
+	var replenished []*Order
+	px, left := equilibrium.Price, equilibrium.Size
+
+	for left.IsPositive() && len(b.Bids) > 0 && len(b.Asks) > 0 {
+		bid, ask := b.Bids[0], b.Asks[0]
+		if !bid.executesAt(px) || !ask.executesAt(px) {
+			break
+		}
+
+		qty := decimal.Min(left, decimal.Min(bid.LeavesQty(), ask.LeavesQty()))
+		left = left.Sub(qty)
+
+		bid.fill(px, qty)
+		ask.fill(px, qty)
+
+		aggressor, resting := bid, ask
+		if ask.TransactTime.After(bid.TransactTime) {
+			aggressor, resting = ask, bid
+		}
+
+		if aggressor.consume(qty) {
+			replenished = append(replenished, aggressor)
+		}
+		restingReplenished := resting.consume(qty)
+		if restingReplenished {
+			replenished = append(replenished, resting)
+		}
+
+		fills = append(fills, Fill{
+			TradeID:     uuid.NewString(),
+			Symbol:      b.Symbol,
+			Price:       px,
+			Quantity:    qty,
+			Time:        now,
+			Aggressor:   *aggressor,
+			Resting:     *resting,
+			Replenished: restingReplenished,
+		})
+
+		if bid.IsFinal() {
+			b.Bids = b.Bids[1:]
+		}
+		if ask.IsFinal() {
+			b.Asks = b.Asks[1:]
+		}
+	}
+
+	for _, order := range replenished {
+		if !order.IsFinal() && b.remove(order) {
+			b.insert(order)
+		}
+	}
+
+	if len(fills) > 0 {
+		b.LastTrade = &fills[len(fills)-1]
+	}
+
+	return fills
+}
+
+// cancelMarketOrders takes the market orders out of the book, they only rest
+// in it until the auction uncrosses. It returns their snapshots.
+func (b *Book) cancelMarketOrders() []Order {
+	var canceled []Order
+
+	for _, side := range []*[]*Order{&b.Bids, &b.Asks} {
+		kept := (*side)[:0]
+		for _, order := range *side {
+			if order.IsMarket() {
+				order.Status = enum.OrdStatus_CANCELED
+				canceled = append(canceled, *order)
+			} else {
+				kept = append(kept, order)
+			}
+		}
+		*side = kept
+	}
+
+	return canceled
+}
+
+// available returns the quantity of the opposite side of the book the order
+// crosses.
+func (b *Book) available(order *Order) decimal.Decimal {
+	opposite := b.Asks
+	if !order.IsBuy() {
+		opposite = b.Bids
+	}
+
+	qty := decimal.Zero
+	for _, resting := range opposite {
+		if !order.crosses(resting) {
+			break
+		}
+		qty = qty.Add(resting.LeavesQty())
+	}
+
+	return qty
+}
+
+// levels aggregates the orders of a book side by price, best price first. A
+// depth of 0 means the full side. Market orders resting during auctions have
+// no price to be shown at.
+func levels(side []*Order, depth int) []Level {
+	var lvls []Level
+
+	for _, order := range side {
+		if order.IsMarket() {
+			continue
+		}
+
+		if len(lvls) > 0 && lvls[len(lvls)-1].Price.Equal(order.Price) {
+			lvls[len(lvls)-1].Size = lvls[len(lvls)-1].Size.Add(order.Visible())
+			lvls[len(lvls)-1].Orders++
+			continue
+		}
+
+		if depth > 0 && len(lvls) == depth {
+			break
+		}
+
+		lvls = append(lvls, Level{
+			Price:  order.Price,
+			Size:   order.Visible(),
+			Orders: 1,
+		})
+	}
+
+	return lvls
+}
+
+// insert adds the order to its side of the book keeping price-time priority,
+// behind the orders at the same price.
+func (b *Book) insert(order *Order) {
+	side := &b.Bids
+	if !order.IsBuy() {
+		side = &b.Asks
+	}
+
+	i := sort.Search(len(*side), func(i int) bool {
+		return order.ahead((*side)[i])
+	})
+
+	*side = append(*side, nil)
+	copy((*side)[i+1:], (*side)[i:])
+	(*side)[i] = order
+}
+
+// remove takes the order out of the book, it returns false if the order was
+// not resting in the book.
+func (b *Book) remove(order *Order) bool {
+	side := &b.Bids
+	if !order.IsBuy() {
+		side = &b.Asks
+	}
+
+	for i, o := range *side {
+		if o == order {
+			*side = append((*side)[:i], (*side)[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,678 @@
+package engine
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/quickfixgo/enum"
+	"github.com/shopspring/decimal"
+)
+
+var epoch = time.Date(2023, 8, 1, 9, 0, 0, 0, time.UTC)
+
+// limit returns a limit order, newAuctionBook timestamps the orders in the
+// order they are given.
+func limit(side enum.Side, qty, px string) *Order {
+	return &Order{
+		Side:     side,
+		Type:     enum.OrdType_LIMIT,
+		Price:    decimal.RequireFromString(px),
+		Quantity: decimal.RequireFromString(qty),
+		Status:   enum.OrdStatus_NEW,
+	}
+}
+
+func market(side enum.Side, qty string) *Order {
+	return &Order{
+		Side:     side,
+		Type:     enum.OrdType_MARKET,
+		Quantity: decimal.RequireFromString(qty),
+		Status:   enum.OrdStatus_NEW,
+	}
+}
+
+func iceberg(side enum.Side, qty, display, px string) *Order {
+	order := limit(side, qty, px)
+	order.DisplayQty = decimal.RequireFromString(display)
+	order.replenish()
+	return order
+}
+
+func stop(side enum.Side, qty, stopPx string) *Order {
+	return &Order{
+		Side:     side,
+		Type:     enum.OrdType_STOP_STOP_LOSS,
+		StopPx:   decimal.RequireFromString(stopPx),
+		Quantity: decimal.RequireFromString(qty),
+		Status:   enum.OrdStatus_NEW,
+	}
+}
+
+func fok(order *Order) *Order {
+	order.TimeInForce = enum.TimeInForce_FILL_OR_KILL
+	return order
+}
+
+func newBook(lastTrade string, orders ...*Order) *Book {
+	book := NewBook("EURUSD")
+
+	if len(lastTrade) > 0 {
+		book.LastTrade = &Fill{Price: decimal.RequireFromString(lastTrade)}
+	}
+
+	for i, order := range orders {
+		order.TransactTime = epoch.Add(time.Duration(i) * time.Second)
+		book.insert(order)
+	}
+
+	return book
+}
+
+func newAuctionBook(lastTrade string, orders ...*Order) *Book {
+	book := newBook(lastTrade, orders...)
+	book.auction = true
+
+	return book
+}
+
+// newEngine submits the orders to an engine in turn, their OrderID and
+// ClOrdID are their positions.
+func newEngine(orders ...*Order) *Engine {
+	e := NewEngine()
+	for i, order := range orders {
+		order.OrderID = strconv.Itoa(i)
+		order.ClOrdID = order.OrderID
+		order.Symbol = "EURUSD"
+		order.TransactTime = epoch.Add(time.Duration(i) * time.Second)
+		e.Submit(order)
+	}
+
+	return e
+}
+
+func TestBookMatch(t *testing.T) {
+	buy, sell := enum.Side_BUY, enum.Side_SELL
+
+	tests := []struct {
+		name     string
+		resting  []*Order
+		incoming *Order
+		trades   []string
+		prices   []string
+		leaves   string
+		asks     int
+	}{
+		{
+			name:     "no cross",
+			resting:  []*Order{limit(sell, "10", "101")},
+			incoming: limit(buy, "10", "100"),
+			leaves:   "10",
+			asks:     1,
+		},
+		{
+			name: "price-time priority",
+			resting: []*Order{
+				limit(sell, "10", "101"),
+				limit(sell, "10", "100"),
+				limit(sell, "10", "100"),
+			},
+			incoming: limit(buy, "15", "101"),
+			trades:   []string{"10", "5"},
+			prices:   []string{"100", "100"},
+			leaves:   "0",
+			asks:     2,
+		},
+		{
+			name: "limit price",
+			resting: []*Order{
+				limit(sell, "10", "100"),
+				limit(sell, "10", "102"),
+			},
+			incoming: limit(buy, "15", "101"),
+			trades:   []string{"10"},
+			prices:   []string{"100"},
+			leaves:   "5",
+			asks:     1,
+		},
+		{
+			name: "market order",
+			resting: []*Order{
+				limit(sell, "10", "100"),
+				limit(sell, "10", "101"),
+			},
+			incoming: market(buy, "15"),
+			trades:   []string{"10", "5"},
+			prices:   []string{"100", "101"},
+			leaves:   "0",
+			asks:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := newBook("", tt.resting...)
+
+			fills := book.match(tt.incoming, epoch.Add(time.Minute))
+
+			if len(fills) != len(tt.trades) {
+				t.Fatalf("%d trades, want %d", len(fills), len(tt.trades))
+			}
+
+			for i, fill := range fills {
+				if !fill.Quantity.Equal(decimal.RequireFromString(tt.trades[i])) {
+					t.Errorf("trade %d quantity = %s, want %s", i, fill.Quantity, tt.trades[i])
+				}
+				if !fill.Price.Equal(decimal.RequireFromString(tt.prices[i])) {
+					t.Errorf("trade %d price = %s, want %s", i, fill.Price, tt.prices[i])
+				}
+			}
+
+			if leaves := tt.incoming.LeavesQty(); !leaves.Equal(decimal.RequireFromString(tt.leaves)) {
+				t.Errorf("leaves = %s, want %s", leaves, tt.leaves)
+			}
+
+			if len(book.Asks) != tt.asks {
+				t.Errorf("%d asks left, want %d", len(book.Asks), tt.asks)
+			}
+		})
+	}
+}
+
+func TestEngineFillOrKill(t *testing.T) {
+	buy, sell := enum.Side_BUY, enum.Side_SELL
+
+	tests := []struct {
+		name    string
+		resting []*Order
+		order   *Order
+		trades  int
+		status  enum.OrdStatus
+	}{
+		{
+			name:    "filled in full",
+			resting: []*Order{limit(sell, "10", "100"), limit(sell, "10", "101")},
+			order:   fok(limit(buy, "15", "101")),
+			trades:  2,
+			status:  enum.OrdStatus_FILLED,
+		},
+		{
+			name:    "not enough quantity",
+			resting: []*Order{limit(sell, "10", "100")},
+			order:   fok(limit(buy, "15", "100")),
+			status:  enum.OrdStatus_CANCELED,
+		},
+		{
+			name:    "not enough quantity at the limit price",
+			resting: []*Order{limit(sell, "10", "100"), limit(sell, "10", "102")},
+			order:   fok(limit(buy, "15", "101")),
+			status:  enum.OrdStatus_CANCELED,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEngine(tt.resting...)
+			tt.order.Symbol = "EURUSD"
+
+			fills := e.Submit(tt.order)
+
+			if len(fills) != tt.trades {
+				t.Fatalf("%d trades, want %d", len(fills), tt.trades)
+			}
+
+			if tt.order.Status != tt.status {
+				t.Errorf("status = %s, want %s", tt.order.Status, tt.status)
+			}
+
+			// Killed orders leave the book untouched
+			if tt.trades == 0 {
+				for _, resting := range tt.resting {
+					if resting.CumQty.IsPositive() {
+						t.Errorf("resting order %s traded %s", resting.OrderID, resting.CumQty)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEngineTrigger(t *testing.T) {
+	buy, sell := enum.Side_BUY, enum.Side_SELL
+
+	tests := []struct {
+		name      string
+		stops     []*Order
+		price     string
+		triggered []string
+		armed     int
+	}{
+		{
+			name:  "buy stop below its stop price",
+			stops: []*Order{stop(buy, "10", "101")},
+			price: "100.5",
+			armed: 1,
+		},
+		{
+			name:      "buy stop at its stop price",
+			stops:     []*Order{stop(buy, "10", "101")},
+			price:     "101",
+			triggered: []string{"0"},
+		},
+		{
+			name:  "sell stop above its stop price",
+			stops: []*Order{stop(sell, "10", "99")},
+			price: "99.5",
+			armed: 1,
+		},
+		{
+			name:      "sell stop below its stop price",
+			stops:     []*Order{stop(sell, "10", "99")},
+			price:     "98",
+			triggered: []string{"0"},
+		},
+		{
+			name: "oldest first",
+			stops: []*Order{
+				stop(buy, "10", "101"),
+				stop(buy, "10", "100"),
+				stop(buy, "10", "103"),
+			},
+			price:     "102",
+			triggered: []string{"0", "1"},
+			armed:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEngine(tt.stops...)
+
+			triggered := e.Trigger("EURUSD", decimal.RequireFromString(tt.price))
+
+			if len(triggered) != len(tt.triggered) {
+				t.Fatalf("%d stops triggered, want %d", len(triggered), len(tt.triggered))
+			}
+
+			for i, order := range triggered {
+				if order.OrderID != tt.triggered[i] {
+					t.Errorf("stop %d triggered is %s, want %s", i, order.OrderID, tt.triggered[i])
+				}
+				if !order.IsMarket() {
+					t.Errorf("triggered stop %s is not a market order", order.OrderID)
+				}
+			}
+
+			if armed := len(e.stops["EURUSD"]); armed != tt.armed {
+				t.Errorf("%d stops armed, want %d", armed, tt.armed)
+			}
+		})
+	}
+}
+
+func TestEngineTriggeredStopMatches(t *testing.T) {
+	ask := limit(enum.Side_SELL, "10", "101")
+	order := stop(enum.Side_BUY, "15", "100")
+	e := newEngine(ask, order)
+
+	if asks := e.Book("EURUSD").Asks; len(asks) != 1 || ask.CumQty.IsPositive() {
+		t.Fatalf("armed stop traded")
+	}
+
+	for _, triggered := range e.Trigger("EURUSD", decimal.RequireFromString("100")) {
+		fills := e.Submit(triggered)
+		if len(fills) != 1 || !fills[0].Price.Equal(ask.Price) {
+			t.Fatalf("triggered stop trades %v, want one trade at %s", fills, ask.Price)
+		}
+	}
+
+	if order.Status != enum.OrdStatus_CANCELED || !order.CumQty.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("stop %s with %s traded, want its remainder canceled after 10", order.Status, order.CumQty)
+	}
+}
+
+func TestBookIcebergReplenishment(t *testing.T) {
+	buy, sell := enum.Side_BUY, enum.Side_SELL
+
+	tests := []struct {
+		name        string
+		auction     bool
+		trades      []string
+		replenished []bool
+	}{
+		{
+			name:        "continuous",
+			trades:      []string{"10", "5"},
+			replenished: []bool{true, false},
+		},
+		{
+			name:        "uncross",
+			auction:     true,
+			trades:      []string{"15"},
+			replenished: []bool{true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hidden := iceberg(sell, "30", "10", "100")
+			other := limit(sell, "10", "100")
+			incoming := limit(buy, "15", "100")
+
+			var fills []Fill
+			if tt.auction {
+				book := newAuctionBook("", hidden, other, incoming)
+				book.auction = false
+				fills = book.uncross(epoch.Add(time.Minute))
+				testIcebergBehind(t, book, hidden, other)
+			} else {
+				book := newBook("", hidden, other)
+				fills = book.match(incoming, epoch.Add(time.Minute))
+				testIcebergBehind(t, book, hidden, other)
+			}
+
+			if len(fills) != len(tt.trades) {
+				t.Fatalf("%d trades, want %d", len(fills), len(tt.trades))
+			}
+
+			for i, fill := range fills {
+				if !fill.Quantity.Equal(decimal.RequireFromString(tt.trades[i])) {
+					t.Errorf("trade %d quantity = %s, want %s", i, fill.Quantity, tt.trades[i])
+				}
+				if fill.Replenished != tt.replenished[i] {
+					t.Errorf("trade %d replenished = %t, want %t", i, fill.Replenished, tt.replenished[i])
+				}
+			}
+
+			if visible := hidden.Visible(); !visible.Equal(decimal.NewFromInt(10)) {
+				t.Errorf("iceberg shows %s, want a new peak of 10", visible)
+			}
+		})
+	}
+}
+
+// testIcebergBehind checks that the replenished iceberg order lost its time
+// priority to the other order at its price.
+func testIcebergBehind(t *testing.T, book *Book, iceberg, other *Order) {
+	t.Helper()
+
+	if len(book.Asks) != 2 || book.Asks[0] != other || book.Asks[1] != iceberg {
+		t.Errorf("replenished iceberg is not behind the other order at its price")
+	}
+}
+
+func TestBookEquilibrium(t *testing.T) {
+	buy, sell := enum.Side_BUY, enum.Side_SELL
+
+	tests := []struct {
+		name      string
+		lastTrade string
+		orders    []*Order
+		crosses   bool
+		price     string
+		size      string
+	}{
+		{
+			name:   "empty book",
+			orders: nil,
+		},
+		{
+			name:   "no cross",
+			orders: []*Order{limit(buy, "10", "99"), limit(sell, "10", "100")},
+		},
+		{
+			name: "maximum volume",
+			orders: []*Order{
+				limit(buy, "10", "102"),
+				limit(buy, "10", "101"),
+				limit(buy, "20", "100"),
+				limit(sell, "5", "99"),
+				limit(sell, "25", "100"),
+				limit(sell, "10", "103"),
+			},
+			crosses: true,
+			price:   "100",
+			size:    "30",
+		},
+		{
+			name: "minimum imbalance",
+			orders: []*Order{
+				limit(buy, "10", "101"),
+				limit(buy, "5", "100"),
+				limit(sell, "10", "100"),
+			},
+			crosses: true,
+			price:   "101",
+			size:    "10",
+		},
+		{
+			name:      "closest to last trade",
+			lastTrade: "102",
+			orders: []*Order{
+				limit(buy, "10", "101"),
+				limit(sell, "10", "99"),
+			},
+			crosses: true,
+			price:   "101",
+			size:    "10",
+		},
+		{
+			name:      "lowest when as close to last trade",
+			lastTrade: "100",
+			orders: []*Order{
+				limit(buy, "10", "101"),
+				limit(sell, "10", "99"),
+			},
+			crosses: true,
+			price:   "99",
+			size:    "10",
+		},
+		{
+			name: "lowest without last trade",
+			orders: []*Order{
+				limit(buy, "10", "101"),
+				limit(sell, "10", "99"),
+			},
+			crosses: true,
+			price:   "99",
+			size:    "10",
+		},
+		{
+			name: "market order against limit orders",
+			orders: []*Order{
+				market(buy, "15"),
+				limit(sell, "10", "100"),
+				limit(sell, "10", "101"),
+			},
+			crosses: true,
+			price:   "101",
+			size:    "15",
+		},
+		{
+			name: "market orders at the limit price",
+			orders: []*Order{
+				market(buy, "10"),
+				market(sell, "10"),
+				limit(buy, "5", "100"),
+			},
+			crosses: true,
+			price:   "100",
+			size:    "10",
+		},
+		{
+			name:      "market orders at the last trade price",
+			lastTrade: "100.5",
+			orders:    []*Order{market(buy, "10"), market(sell, "5")},
+			crosses:   true,
+			price:     "100.5",
+			size:      "5",
+		},
+		{
+			name:   "market orders without reference price",
+			orders: []*Order{market(buy, "10"), market(sell, "5")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := newAuctionBook(tt.lastTrade, tt.orders...)
+
+			level, ok := book.equilibrium()
+			if ok != tt.crosses {
+				t.Fatalf("crosses = %t, want %t", ok, tt.crosses)
+			}
+
+			if !ok {
+				return
+			}
+
+			if !level.Price.Equal(decimal.RequireFromString(tt.price)) {
+				t.Errorf("price = %s, want %s", level.Price, tt.price)
+			}
+
+			if !level.Size.Equal(decimal.RequireFromString(tt.size)) {
+				t.Errorf("size = %s, want %s", level.Size, tt.size)
+			}
+		})
+	}
+}
+
+func TestBookUncross(t *testing.T) {
+	buy, sell := enum.Side_BUY, enum.Side_SELL
+
+	tests := []struct {
+		name      string
+		lastTrade string
+		orders    []*Order
+		trades    []string
+		price     string
+		bids      int
+		asks      int
+		canceled  int
+	}{
+		{
+			name:   "no cross",
+			orders: []*Order{limit(buy, "10", "99"), limit(sell, "10", "100")},
+			bids:   1,
+			asks:   1,
+		},
+		{
+			name: "price-time priority",
+			orders: []*Order{
+				limit(buy, "10", "100"),
+				limit(buy, "10", "101"),
+				limit(sell, "15", "99"),
+			},
+			trades: []string{"10", "5"},
+			price:  "99",
+			bids:   1,
+		},
+		{
+			name: "remainder rests",
+			orders: []*Order{
+				limit(buy, "20", "101"),
+				limit(sell, "5", "100"),
+				limit(sell, "5", "101"),
+			},
+			trades: []string{"5", "5"},
+			price:  "101",
+			bids:   1,
+		},
+		{
+			name: "market orders first",
+			orders: []*Order{
+				limit(buy, "10", "101"),
+				market(buy, "10"),
+				limit(sell, "10", "100"),
+			},
+			trades: []string{"10"},
+			price:  "100",
+			bids:   1,
+		},
+		{
+			name: "market order remainder canceled",
+			orders: []*Order{
+				market(buy, "15"),
+				limit(sell, "10", "100"),
+			},
+			trades:   []string{"10"},
+			price:    "100",
+			canceled: 1,
+		},
+		{
+			name:      "market orders at the last trade price",
+			lastTrade: "100",
+			orders:    []*Order{market(buy, "10"), market(sell, "4")},
+			trades:    []string{"4"},
+			price:     "100",
+			canceled:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := newAuctionBook(tt.lastTrade, tt.orders...)
+			book.auction = false
+
+			fills := book.uncross(epoch.Add(time.Minute))
+			canceled := book.cancelMarketOrders()
+
+			if len(fills) != len(tt.trades) {
+				t.Fatalf("%d trades, want %d", len(fills), len(tt.trades))
+			}
+
+			for i, fill := range fills {
+				if !fill.Price.Equal(decimal.RequireFromString(tt.price)) {
+					t.Errorf("trade %d price = %s, want %s", i, fill.Price, tt.price)
+				}
+				if !fill.Quantity.Equal(decimal.RequireFromString(tt.trades[i])) {
+					t.Errorf("trade %d quantity = %s, want %s", i, fill.Quantity, tt.trades[i])
+				}
+			}
+
+			if len(book.Bids) != tt.bids || len(book.Asks) != tt.asks {
+				t.Errorf("%d bids and %d asks left, want %d and %d", len(book.Bids), len(book.Asks), tt.bids, tt.asks)
+			}
+
+			if len(canceled) != tt.canceled {
+				t.Errorf("%d market orders canceled, want %d", len(canceled), tt.canceled)
+			}
+
+			for _, order := range canceled {
+				if order.Status != enum.OrdStatus_CANCELED {
+					t.Errorf("canceled order status = %s, want %s", order.Status, enum.OrdStatus_CANCELED)
+				}
+			}
+		})
+	}
+}
+
+func TestEngineEvict(t *testing.T) {
+	resting := limit(enum.Side_SELL, "20", "100")
+	filled := limit(enum.Side_BUY, "10", "100")
+	e := newEngine(resting, filled)
+
+	if evicted := e.EvictOrders(epoch, time.Hour); evicted != 0 {
+		t.Fatalf("%d orders evicted as soon as final, want 0", evicted)
+	}
+
+	if evicted := e.EvictOrders(epoch.Add(time.Hour), time.Hour); evicted != 1 {
+		t.Fatalf("%d orders evicted after the retention, want 1", evicted)
+	}
+
+	if _, err := e.Lookup(filled.SessionID, "", filled.ClOrdID); err == nil {
+		t.Errorf("evicted order can still be looked up")
+	}
+
+	if open := e.OpenOrders(func(*Order) bool { return true }); open != 1 {
+		t.Errorf("%d open orders left, want 1", open)
+	}
+
+	trades := e.Trades(func(*Fill) bool { return true })
+	if evicted := e.EvictTrades(trades[0].Time); evicted != 0 {
+		t.Errorf("%d trades evicted at their time, want 0", evicted)
+	}
+	if evicted := e.EvictTrades(trades[0].Time.Add(time.Nanosecond)); evicted != 1 {
+		t.Errorf("%d trades evicted after their time, want 1", evicted)
+	}
+}
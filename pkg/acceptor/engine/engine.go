@@ -0,0 +1,507 @@
+package engine
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+type clOrdIDKey struct {
+	sessionID quickfix.SessionID
+	clOrdID   string
+}
+
+// Engine holds one order book per symbol and matches incoming orders against
+// them. Stop orders are held by symbol until they are triggered. Orders in a
+// final state and past trades are kept until they are evicted.
+type Engine struct {
+	books    map[string]*Book
+	stops    map[string][]*Order
+	orders   map[string]*Order
+	clOrdIDs map[clOrdIDKey]*Order
+	trades   []Fill
+	mux      sync.Mutex
+
+	// finalSince is when EvictOrders first saw the orders in a final state.
+	finalSince map[string]time.Time
+}
+
+func NewEngine() *Engine {
+	return &Engine{
+		books:      make(map[string]*Book),
+		stops:      make(map[string][]*Order),
+		orders:     make(map[string]*Order),
+		clOrdIDs:   make(map[clOrdIDKey]*Order),
+		finalSince: make(map[string]time.Time),
+	}
+}
+
+// Submit matches the order against the book of its symbol and rests what is
+// left of it. Market, IOC and FOK orders never rest, their remaining quantity
+// is canceled. FOK orders are canceled without trading if they can not be
+// filled in full. During auctions orders rest without matching, IOC and FOK
+// orders are canceled. Stop orders are held until Trigger releases them.
+func (e *Engine) Submit(order *Order) []Fill {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	e.orders[order.OrderID] = order
+	e.clOrdIDs[clOrdIDKey{order.SessionID, order.ClOrdID}] = order
+
+	if order.IsArmed() {
+		e.stops[order.Symbol] = append(e.stops[order.Symbol], order)
+		return nil
+	}
+
+	book := e.book(order.Symbol)
+	order.replenish()
+
+	if book.auction {
+		if order.HasImmediateTimeInForce() {
+			order.Status = enum.OrdStatus_CANCELED
+		} else {
+			book.insert(order)
+		}
+		return nil
+	}
+
+	if order.TimeInForce == enum.TimeInForce_FILL_OR_KILL && book.available(order).LessThan(order.LeavesQty()) {
+		order.Status = enum.OrdStatus_CANCELED
+		return nil
+	}
+
+	fills := book.match(order, time.Now())
+	e.trades = append(e.trades, fills...)
+
+	if order.LeavesQty().IsPositive() {
+		if order.IsImmediate() {
+			order.Status = enum.OrdStatus_CANCELED
+		} else {
+			book.insert(order)
+		}
+	}
+
+	return fills
+}
+
+// Restore puts back in the book an order which was resting in it, without
+// matching it.
+func (e *Engine) Restore(order *Order) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	order.notional = order.AvgPx.Mul(order.CumQty)
+
+	e.orders[order.OrderID] = order
+	e.clOrdIDs[clOrdIDKey{order.SessionID, order.ClOrdID}] = order
+
+	if order.IsArmed() {
+		e.stops[order.Symbol] = append(e.stops[order.Symbol], order)
+		return
+	}
+
+	order.replenish()
+	e.book(order.Symbol).insert(order)
+}
+
+// Trigger releases the stop orders of the symbol triggered by price, oldest
+// first. They have to be submitted again to be matched.
+func (e *Engine) Trigger(symbol string, price decimal.Decimal) []*Order {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	var triggered []*Order
+	stops := e.stops[symbol][:0]
+	for _, order := range e.stops[symbol] {
+		if order.IsTriggeredBy(price) {
+			order.Triggered = true
+			triggered = append(triggered, order)
+		} else {
+			stops = append(stops, order)
+		}
+	}
+	e.stops[symbol] = stops
+
+	return triggered
+}
+
+// Expire takes the orders whose expire time is past out of the book and
+// returns their snapshots.
+func (e *Engine) Expire(now time.Time) []Order {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	var expired []Order
+	for _, order := range e.orders {
+		if order.IsFinal() || order.ExpireTime.IsZero() || order.ExpireTime.After(now) {
+			continue
+		}
+
+		e.remove(order)
+		order.Status = enum.OrdStatus_EXPIRED
+		expired = append(expired, *order)
+	}
+
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].TransactTime.Before(expired[j].TransactTime)
+	})
+
+	return expired
+}
+
+// Execute fills quantity of the order at price outside of the book, as if it
+// traded with a counterparty unknown to the engine. The fill has no resting
+// order.
+func (e *Engine) Execute(order *Order, price, quantity decimal.Decimal) Fill {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	e.orders[order.OrderID] = order
+	e.clOrdIDs[clOrdIDKey{order.SessionID, order.ClOrdID}] = order
+
+	order.fill(price, quantity)
+
+	fill := Fill{
+		TradeID:   uuid.NewString(),
+		Symbol:    order.Symbol,
+		Price:     price,
+		Quantity:  quantity,
+		Time:      time.Now(),
+		Aggressor: *order,
+	}
+
+	e.book(order.Symbol).LastTrade = &fill
+	e.trades = append(e.trades, fill)
+
+	return fill
+}
+
+// Lookup returns the order of the session identified by orderID or, if
+// orderID is empty, by clOrdID. Orders in a final state are returned as well.
+func (e *Engine) Lookup(sessionID quickfix.SessionID, orderID, clOrdID string) (*Order, error) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	if len(orderID) > 0 {
+		if order, ok := e.orders[orderID]; ok && order.SessionID == sessionID {
+			return order, nil
+		}
+	} else if order, ok := e.clOrdIDs[clOrdIDKey{sessionID, clOrdID}]; ok {
+		return order, nil
+	}
+
+	return nil, errors.AcceptorOrderNotFound
+}
+
+// Cancel removes the order from the book. The order is known by clOrdID
+// afterwards.
+func (e *Engine) Cancel(order *Order, clOrdID string) error {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	if order.IsFinal() {
+		return errors.AcceptorOrderFinal
+	}
+
+	e.remove(order)
+	e.rename(order, clOrdID)
+	order.Status = enum.OrdStatus_CANCELED
+
+	return nil
+}
+
+// CancelAll takes the orders not in a final state for which match returns true
+// out of the book and returns their snapshots.
+func (e *Engine) CancelAll(match func(*Order) bool) []Order {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	var canceled []Order
+	for _, order := range e.orders {
+		if order.IsFinal() || !match(order) {
+			continue
+		}
+
+		e.remove(order)
+		order.Status = enum.OrdStatus_CANCELED
+		canceled = append(canceled, *order)
+	}
+
+	sort.Slice(canceled, func(i, j int) bool {
+		return canceled[i].TransactTime.Before(canceled[j].TransactTime)
+	})
+
+	return canceled
+}
+
+// Replace amends the quantity, price and stop price of the order. The order
+// keeps its time priority unless its price changes or its quantity increases,
+// in which case it is matched again as if it was a new order. Stop orders
+// waiting for their trigger are only amended.
+func (e *Engine) Replace(order *Order, clOrdID string, quantity, price, stopPx decimal.Decimal) ([]Fill, error) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	if order.IsFinal() {
+		return nil, errors.AcceptorOrderFinal
+	}
+
+	if quantity.LessThanOrEqual(order.CumQty) {
+		return nil, errors.AcceptorOrderQuantityTooLow
+	}
+
+	e.rename(order, clOrdID)
+
+	if order.IsArmed() {
+		order.Quantity = quantity
+		order.Price = price
+		order.StopPx = stopPx
+		return nil, nil
+	}
+
+	book := e.book(order.Symbol)
+	if price.Equal(order.Price) && quantity.LessThanOrEqual(order.Quantity) {
+		order.Quantity = quantity
+		return nil, nil
+	}
+
+	book.remove(order)
+	order.Quantity = quantity
+	order.Price = price
+	order.replenish()
+
+	if book.auction {
+		book.insert(order)
+		return nil, nil
+	}
+
+	fills := book.match(order, time.Now())
+	e.trades = append(e.trades, fills...)
+	if order.LeavesQty().IsPositive() {
+		book.insert(order)
+	}
+
+	return fills, nil
+}
+
+// StartAuction stops matching the orders of the symbol until Uncross is
+// called.
+func (e *Engine) StartAuction(symbol string) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	e.book(symbol).auction = true
+}
+
+// InAuction returns true if the book of the symbol is in an auction.
+func (e *Engine) InAuction(symbol string) bool {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	return e.book(symbol).auction
+}
+
+// Indicative returns the price at which the book of the symbol would uncross
+// and the quantity it would execute. It returns false if the book is not in an
+// auction or does not cross.
+func (e *Engine) Indicative(symbol string) (Level, bool) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	book := e.book(symbol)
+	if !book.auction {
+		return Level{}, false
+	}
+
+	return book.equilibrium()
+}
+
+// Uncross ends the auction of the symbol, executing all the crossing orders
+// at the equilibrium price, and resumes matching. What is left of the market
+// orders is canceled, their snapshots are returned along with the fills.
+func (e *Engine) Uncross(symbol string) ([]Fill, []Order) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	book := e.book(symbol)
+	book.auction = false
+
+	fills := book.uncross(time.Now())
+	e.trades = append(e.trades, fills...)
+
+	return fills, book.cancelMarketOrders()
+}
+
+// Depth returns the aggregated bid and offer levels of the symbol book.
+func (e *Engine) Depth(symbol string, depth int) ([]Level, []Level) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	book := e.book(symbol)
+
+	return levels(book.Bids, depth), levels(book.Asks, depth)
+}
+
+// LastTrade returns the last trade of the symbol or nil if none happened.
+func (e *Engine) LastTrade(symbol string) *Fill {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	return e.book(symbol).LastTrade
+}
+
+// Orders returns a snapshot of the orders not in a final state sorted by
+// transaction time.
+func (e *Engine) Orders() []Order {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	orders := make([]Order, 0, len(e.orders))
+	for _, order := range e.orders {
+		if !order.IsFinal() {
+			orders = append(orders, *order)
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].TransactTime.Before(orders[j].TransactTime)
+	})
+
+	return orders
+}
+
+// Trades returns the trades for which match returns true in the order they
+// happened.
+func (e *Engine) Trades(match func(*Fill) bool) []Fill {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	var trades []Fill
+	for i := range e.trades {
+		if match(&e.trades[i]) {
+			trades = append(trades, e.trades[i])
+		}
+	}
+
+	return trades
+}
+
+// OpenOrders returns the number of orders not in a final state for which
+// match returns true.
+func (e *Engine) OpenOrders(match func(*Order) bool) int {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	count := 0
+	for _, order := range e.orders {
+		if !order.IsFinal() && match(order) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// EvictOrders forgets the orders which have been in a final state for at
+// least retention, they can not be looked up anymore. Orders are known to be
+// final from the first call which sees them so.
+func (e *Engine) EvictOrders(now time.Time, retention time.Duration) int {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	evicted := 0
+	for orderID, order := range e.orders {
+		if !order.IsFinal() {
+			continue
+		}
+
+		since, ok := e.finalSince[orderID]
+		if !ok {
+			e.finalSince[orderID] = now
+			continue
+		}
+
+		if now.Sub(since) < retention {
+			continue
+		}
+
+		delete(e.orders, orderID)
+		delete(e.finalSince, orderID)
+		evicted++
+	}
+
+	// Orders are known by all the ClOrdIDs they had
+	if evicted > 0 {
+		for key, order := range e.clOrdIDs {
+			if e.orders[order.OrderID] != order {
+				delete(e.clOrdIDs, key)
+			}
+		}
+	}
+
+	return evicted
+}
+
+// EvictTrades forgets the trades which happened before the given time.
+func (e *Engine) EvictTrades(before time.Time) int {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	i := sort.Search(len(e.trades), func(i int) bool {
+		return !e.trades[i].Time.Before(before)
+	})
+
+	if i > 0 {
+		e.trades = append([]Fill(nil), e.trades[i:]...)
+	}
+
+	return i
+}
+
+// Book returns the order book of the given symbol, creating it if needed.
+func (e *Engine) Book(symbol string) *Book {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	return e.book(symbol)
+}
+
+func (e *Engine) book(symbol string) *Book {
+	book, ok := e.books[symbol]
+	if !ok {
+		book = NewBook(symbol)
+		e.books[symbol] = book
+	}
+	return book
+}
+
+// remove takes the order out of the book or out of the stop orders waiting
+// for their trigger.
+func (e *Engine) remove(order *Order) {
+	if !order.IsArmed() {
+		e.book(order.Symbol).remove(order)
+		return
+	}
+
+	stops := e.stops[order.Symbol]
+	for i, o := range stops {
+		if o == order {
+			e.stops[order.Symbol] = append(stops[:i], stops[i+1:]...)
+			return
+		}
+	}
+}
+
+func (e *Engine) rename(order *Order, clOrdID string) {
+	order.OrigClOrdID = order.ClOrdID
+	order.ClOrdID = clOrdID
+	e.clOrdIDs[clOrdIDKey{order.SessionID, clOrdID}] = order
+}
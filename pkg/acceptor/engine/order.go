@@ -0,0 +1,209 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+)
+
+// Order is an order handled by the matching engine.
+type Order struct {
+	SessionID    quickfix.SessionID
+	OrderID      string
+	ClOrdID      string
+	OrigClOrdID  string
+	Symbol       string
+	Side         enum.Side
+	Type         enum.OrdType
+	TimeInForce  enum.TimeInForce
+	Price        decimal.Decimal
+	Quantity     decimal.Decimal
+	CumQty       decimal.Decimal
+	AvgPx        decimal.Decimal
+	Status       enum.OrdStatus
+	TransactTime time.Time
+
+	// ExpireTime is when the order expires, orders without one stay in the
+	// book until they are filled or canceled.
+	ExpireTime time.Time
+
+	// StopPx is the trigger price of stop and stop-limit orders, they are held
+	// out of the book until they are triggered.
+	StopPx    decimal.Decimal
+	Triggered bool
+
+	// DisplayQty is the peak shown by iceberg orders, the rest of their
+	// quantity is hidden.
+	DisplayQty decimal.Decimal
+
+	notional decimal.Decimal
+	peak     decimal.Decimal
+}
+
+// LeavesQty returns the quantity still open for execution.
+func (o *Order) LeavesQty() decimal.Decimal {
+	if o.IsFinal() {
+		return decimal.Zero
+	}
+
+	return o.Quantity.Sub(o.CumQty)
+}
+
+// IsFinal returns true if the order can not trade anymore.
+func (o *Order) IsFinal() bool {
+	switch o.Status {
+	case enum.OrdStatus_FILLED,
+		enum.OrdStatus_CANCELED,
+		enum.OrdStatus_REJECTED,
+		enum.OrdStatus_EXPIRED,
+		enum.OrdStatus_DONE_FOR_DAY:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsBuy returns true if the order is on the buy side of the book.
+func (o *Order) IsBuy() bool {
+	switch o.Side {
+	case enum.Side_BUY, enum.Side_BUY_MINUS:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsImmediate returns true if what is left of the order after matching must
+// be canceled.
+func (o *Order) IsImmediate() bool {
+	return o.HasImmediateTimeInForce() || o.IsMarket()
+}
+
+// HasImmediateTimeInForce returns true for IOC and FOK orders.
+func (o *Order) HasImmediateTimeInForce() bool {
+	return o.TimeInForce == enum.TimeInForce_IMMEDIATE_OR_CANCEL || o.TimeInForce == enum.TimeInForce_FILL_OR_KILL
+}
+
+// IsMarket returns true if the order trades at any price, triggered stop
+// orders do.
+func (o *Order) IsMarket() bool {
+	return o.Type == enum.OrdType_MARKET || (o.Type == enum.OrdType_STOP_STOP_LOSS && o.Triggered)
+}
+
+// HasPrice returns true if the order has a limit price.
+func (o *Order) HasPrice() bool {
+	return o.Type != enum.OrdType_MARKET && o.Type != enum.OrdType_STOP_STOP_LOSS
+}
+
+// IsStop returns true for stop and stop-limit orders.
+func (o *Order) IsStop() bool {
+	return o.Type == enum.OrdType_STOP_STOP_LOSS || o.Type == enum.OrdType_STOP_LIMIT
+}
+
+// IsArmed returns true if the order is a stop waiting for its trigger.
+func (o *Order) IsArmed() bool {
+	return o.IsStop() && !o.Triggered && !o.IsFinal()
+}
+
+// IsTriggeredBy returns true if price reaches the stop price of the order:
+// buy stops trigger at or above it, sell stops at or below it.
+func (o *Order) IsTriggeredBy(price decimal.Decimal) bool {
+	if o.IsBuy() {
+		return price.GreaterThanOrEqual(o.StopPx)
+	}
+	return price.LessThanOrEqual(o.StopPx)
+}
+
+// IsIceberg returns true if the order hides part of its quantity.
+func (o *Order) IsIceberg() bool {
+	return o.DisplayQty.IsPositive() && o.DisplayQty.LessThan(o.Quantity)
+}
+
+// Visible returns the quantity of the order shown in the book, the current
+// peak of iceberg orders.
+func (o *Order) Visible() decimal.Decimal {
+	if !o.IsIceberg() {
+		return o.LeavesQty()
+	}
+	return decimal.Min(o.peak, o.LeavesQty())
+}
+
+// replenish shows a new peak of the iceberg order.
+func (o *Order) replenish() {
+	if o.IsIceberg() {
+		o.peak = decimal.Min(o.DisplayQty, o.LeavesQty())
+	}
+}
+
+// consume takes qty off the peak of the iceberg order and shows a new peak
+// once it is exhausted, it returns true if it did.
+func (o *Order) consume(qty decimal.Decimal) bool {
+	if !o.IsIceberg() || o.IsFinal() {
+		return false
+	}
+
+	o.peak = o.peak.Sub(qty)
+	if o.peak.IsPositive() {
+		return false
+	}
+
+	o.replenish()
+	return true
+}
+
+// fill records an execution of qty at px against the order.
+func (o *Order) fill(px, qty decimal.Decimal) {
+	o.CumQty = o.CumQty.Add(qty)
+	o.notional = o.notional.Add(px.Mul(qty))
+	o.AvgPx = o.notional.Div(o.CumQty)
+
+	if o.CumQty.GreaterThanOrEqual(o.Quantity) {
+		o.Status = enum.OrdStatus_FILLED
+	} else {
+		o.Status = enum.OrdStatus_PARTIALLY_FILLED
+	}
+}
+
+// executesAt returns true if the order can trade at px, market orders trade at
+// any price.
+func (o *Order) executesAt(px decimal.Decimal) bool {
+	switch {
+	case o.IsMarket():
+		return true
+	case o.IsBuy():
+		return o.Price.GreaterThanOrEqual(px)
+	default:
+		return o.Price.LessThanOrEqual(px)
+	}
+}
+
+// ahead returns true if the order has price priority over the other order of
+// the same side, market orders come first.
+func (o *Order) ahead(other *Order) bool {
+	switch {
+	case o.IsMarket():
+		return !other.IsMarket()
+	case other.IsMarket():
+		return false
+	case o.IsBuy():
+		return o.Price.GreaterThan(other.Price)
+	default:
+		return o.Price.LessThan(other.Price)
+	}
+}
+
+// crosses returns true if the order can trade against the resting order.
+func (o *Order) crosses(resting *Order) bool {
+	if o.IsMarket() {
+		return true
+	}
+
+	if o.IsBuy() {
+		return o.Price.GreaterThanOrEqual(resting.Price)
+	}
+
+	return o.Price.LessThanOrEqual(resting.Price)
+}
@@ -0,0 +1,203 @@
+// Package gtc persists the good till cancel orders of the acceptor so that
+// they survive restarts. Orders are kept in the SQL store database of the
+// acceptor.
+package gtc
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/quickfixgo/enum"
+
+	"sylr.dev/fix/pkg/acceptor/engine"
+)
+
+const schema = `CREATE TABLE IF NOT EXISTS gtc_orders (
+	order_id VARCHAR(64) NOT NULL PRIMARY KEY,
+	begin_string VARCHAR(8) NOT NULL,
+	sender_comp_id VARCHAR(64) NOT NULL,
+	sender_sub_id VARCHAR(64) NOT NULL,
+	sender_loc_id VARCHAR(64) NOT NULL,
+	target_comp_id VARCHAR(64) NOT NULL,
+	target_sub_id VARCHAR(64) NOT NULL,
+	target_loc_id VARCHAR(64) NOT NULL,
+	session_qualifier VARCHAR(64) NOT NULL,
+	cl_ord_id VARCHAR(64) NOT NULL,
+	orig_cl_ord_id VARCHAR(64) NOT NULL,
+	symbol VARCHAR(64) NOT NULL,
+	side VARCHAR(1) NOT NULL,
+	ord_type VARCHAR(1) NOT NULL,
+	price VARCHAR(32) NOT NULL,
+	quantity VARCHAR(32) NOT NULL,
+	cum_qty VARCHAR(32) NOT NULL,
+	avg_px VARCHAR(32) NOT NULL,
+	status VARCHAR(1) NOT NULL,
+	transact_time VARCHAR(64) NOT NULL,
+	stop_px VARCHAR(32) NOT NULL,
+	triggered VARCHAR(1) NOT NULL,
+	display_qty VARCHAR(32) NOT NULL
+)`
+
+// migrations add the columns missing from the tables created by former
+// versions of the acceptor.
+var migrations = []struct {
+	column     string
+	definition string
+}{
+	{"stop_px", "VARCHAR(32) NOT NULL DEFAULT '0'"},
+	{"triggered", "VARCHAR(1) NOT NULL DEFAULT 'N'"},
+	{"display_qty", "VARCHAR(32) NOT NULL DEFAULT '0'"},
+}
+
+const columns = `order_id, begin_string, sender_comp_id, sender_sub_id, sender_loc_id,
+	target_comp_id, target_sub_id, target_loc_id, session_qualifier, cl_ord_id,
+	orig_cl_ord_id, symbol, side, ord_type, price, quantity, cum_qty, avg_px, status,
+	transact_time, stop_px, triggered, display_qty`
+
+// Store holds the GTC orders in a SQL database.
+type Store struct {
+	db *sql.DB
+}
+
+// Open connects to the database and creates the table of the orders if
+// needed.
+func Open(driver, dataSourceName string) (*Store, error) {
+	db, err := sql.Open(driver, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err = migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// migrate adds the missing columns to the table of the orders, selecting a
+// column which does not exist fails with every driver.
+func migrate(db *sql.DB) error {
+	for _, m := range migrations {
+		rows, err := db.Query(`SELECT ` + m.column + ` FROM gtc_orders WHERE 1 = 0`)
+		if err == nil {
+			rows.Close()
+			continue
+		}
+
+		if _, err = db.Exec(`ALTER TABLE gtc_orders ADD COLUMN ` + m.column + ` ` + m.definition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Save records the current state of the order.
+func (s *Store) Save(order engine.Order) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM gtc_orders WHERE order_id = $1`, order.OrderID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	id := order.SessionID
+	_, err = tx.Exec(`INSERT INTO gtc_orders (`+columns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)`,
+		order.OrderID, id.BeginString, id.SenderCompID, id.SenderSubID, id.SenderLocationID,
+		id.TargetCompID, id.TargetSubID, id.TargetLocationID, id.Qualifier, order.ClOrdID,
+		order.OrigClOrdID, order.Symbol, string(order.Side), string(order.Type), order.Price.String(),
+		order.Quantity.String(), order.CumQty.String(), order.AvgPx.String(), string(order.Status),
+		order.TransactTime.Format(time.RFC3339Nano), order.StopPx.String(), boolString(order.Triggered),
+		order.DisplayQty.String())
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete forgets the order.
+func (s *Store) Delete(orderID string) error {
+	_, err := s.db.Exec(`DELETE FROM gtc_orders WHERE order_id = $1`, orderID)
+	return err
+}
+
+// Load returns the orders of the store.
+func (s *Store) Load() ([]*engine.Order, error) {
+	rows, err := s.db.Query(`SELECT ` + columns + ` FROM gtc_orders ORDER BY transact_time`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*engine.Order
+	for rows.Next() {
+		var side, ordType, status, price, quantity, cumQty, avgPx, transactTime, stopPx, triggered, displayQty string
+
+		order := &engine.Order{TimeInForce: enum.TimeInForce_GOOD_TILL_CANCEL}
+		id := &order.SessionID
+
+		err = rows.Scan(&order.OrderID, &id.BeginString, &id.SenderCompID, &id.SenderSubID, &id.SenderLocationID,
+			&id.TargetCompID, &id.TargetSubID, &id.TargetLocationID, &id.Qualifier, &order.ClOrdID,
+			&order.OrigClOrdID, &order.Symbol, &side, &ordType, &price, &quantity, &cumQty, &avgPx, &status,
+			&transactTime, &stopPx, &triggered, &displayQty)
+		if err != nil {
+			return nil, err
+		}
+
+		order.Side = enum.Side(side)
+		order.Type = enum.OrdType(ordType)
+		order.Status = enum.OrdStatus(status)
+		order.Triggered = triggered == "Y"
+
+		for _, d := range []struct {
+			value string
+			dest  *decimal.Decimal
+		}{
+			{price, &order.Price},
+			{quantity, &order.Quantity},
+			{cumQty, &order.CumQty},
+			{avgPx, &order.AvgPx},
+			{stopPx, &order.StopPx},
+			{displayQty, &order.DisplayQty},
+		} {
+			if *d.dest, err = decimal.NewFromString(d.value); err != nil {
+				return nil, err
+			}
+		}
+
+		if order.TransactTime, err = time.Parse(time.RFC3339Nano, transactTime); err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// boolString returns the FIX representation of b.
+func boolString(b bool) string {
+	if b {
+		return "Y"
+	}
+	return "N"
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,130 @@
+package refdata
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/quickfixgo/enum"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// Phase is the trading phase of a trading session or of a symbol, it tells
+// whether orders are accepted.
+type Phase string
+
+const (
+	PhasePreOpen        Phase = "pre-open"
+	PhaseOpeningAuction Phase = "opening-auction"
+	PhaseContinuous     Phase = "continuous"
+	PhaseClosingAuction Phase = "closing-auction"
+	PhaseHalted         Phase = "halted"
+	PhaseClosed         Phase = "closed"
+)
+
+var phases = map[Phase]struct {
+	tradSesStatus enum.TradSesStatus
+	tradingStatus enum.SecurityTradingStatus
+}{
+	PhasePreOpen:        {enum.TradSesStatus_PRE_OPEN, enum.SecurityTradingStatus_PRE_OPEN},
+	PhaseOpeningAuction: {enum.TradSesStatus_PRE_OPEN, enum.SecurityTradingStatus_OPENING_ROTATION},
+	PhaseContinuous:     {enum.TradSesStatus_OPEN, enum.SecurityTradingStatus_READY_TO_TRADE},
+	PhaseClosingAuction: {enum.TradSesStatus_PRE_CLOSE, enum.SecurityTradingStatus_PRE_CROSS},
+	PhaseHalted:         {enum.TradSesStatus_HALTED, enum.SecurityTradingStatus_TRADING_HALT},
+	PhaseClosed:         {enum.TradSesStatus_CLOSED, enum.SecurityTradingStatus_NOT_AVAILABLE_FOR_TRADING},
+}
+
+// ParsePhase returns the phase named s.
+func ParsePhase(s string) (Phase, error) {
+	if _, ok := phases[Phase(s)]; !ok {
+		return "", fmt.Errorf("%w: %s", errors.RefDataUnknownPhase, s)
+	}
+	return Phase(s), nil
+}
+
+// AcceptsOrders tells whether new orders and replaces are accepted during the
+// phase, cancels always are.
+func (p Phase) AcceptsOrders() bool {
+	return p == PhaseContinuous || p.IsAuction()
+}
+
+// IsAuction tells whether orders are collected without matching during the
+// phase.
+func (p Phase) IsAuction() bool {
+	return p == PhaseOpeningAuction || p == PhaseClosingAuction
+}
+
+// TradSesStatus returns the FIX status of a trading session in the phase.
+func (p Phase) TradSesStatus() enum.TradSesStatus {
+	return phases[p].tradSesStatus
+}
+
+// SecurityTradingStatus returns the FIX status of a symbol in the phase.
+func (p Phase) SecurityTradingStatus() enum.SecurityTradingStatus {
+	return phases[p].tradingStatus
+}
+
+// PhaseChange starts a phase at a time of the day formatted as 15:04:05.
+type PhaseChange struct {
+	Time  string `yaml:"Time"`
+	Phase Phase  `yaml:"Phase"`
+
+	offset time.Duration
+}
+
+// schedulePhase returns the phase of the schedule at the given time or an
+// empty phase if the schedule is empty. Before the first change of the day,
+// the phase is the last one of the day before.
+func schedulePhase(changes []*PhaseChange, location *time.Location, now time.Time) Phase {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	if location == nil {
+		location = time.UTC
+	}
+
+	t := now.In(location)
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+
+	phase := changes[len(changes)-1].Phase
+	for _, change := range changes {
+		if change.offset > offset {
+			break
+		}
+		phase = change.Phase
+	}
+
+	return phase
+}
+
+// validateSchedule checks the phase changes of a schedule, sorts them by time
+// of the day and returns the location of the time zone.
+func validateSchedule(changes []*PhaseChange, timeZone string) (*time.Location, error) {
+	location := time.UTC
+	if len(timeZone) > 0 {
+		var err error
+		if location, err = time.LoadLocation(timeZone); err != nil {
+			return nil, fmt.Errorf("%w: %s", errors.RefDataInvalidSchedule, err)
+		}
+	}
+
+	for _, change := range changes {
+		if _, ok := phases[change.Phase]; !ok {
+			return nil, fmt.Errorf("%w: %s", errors.RefDataUnknownPhase, change.Phase)
+		}
+
+		t, err := time.Parse("15:04:05", change.Time)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errors.RefDataInvalidSchedule, err)
+		}
+		change.offset = t.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].offset < changes[j].offset
+	})
+
+	return location, nil
+}
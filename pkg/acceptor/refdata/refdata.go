@@ -0,0 +1,194 @@
+package refdata
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/quickfixgo/enum"
+	"github.com/shopspring/decimal"
+
+	yaml "sylr.dev/yaml/v3"
+
+	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/errors"
+)
+
+// Data is the reference data served by the acceptor: the instruments it
+// trades and the trading sessions they belong to.
+type Data struct {
+	Instruments     []*Instrument     `yaml:"instruments"`
+	TradingSessions []*TradingSession `yaml:"trading-sessions"`
+}
+
+type Instrument struct {
+	Symbol           string `yaml:"Symbol"`
+	SecurityID       string `yaml:"SecurityID"`
+	SecurityIDSource string `yaml:"SecurityIDSource"`
+	CFICode          string `yaml:"CFICode"`
+	SecurityType     string `yaml:"SecurityType"`
+	Currency         string `yaml:"Currency"`
+	TradingSessionID string `yaml:"TradingSessionID"`
+	Status           string `yaml:"Status"`
+
+	// PrevClosePx is the reference price of the instrument until it trades.
+	PrevClosePx decimal.Decimal `yaml:"PrevClosePx"`
+
+	// Schedule gives the phases of the instrument through the day in TimeZone,
+	// e.g. its auction windows. Instruments without schedule follow the one
+	// of their trading session.
+	Schedule []*PhaseChange `yaml:"Schedule"`
+	TimeZone string         `yaml:"TimeZone"`
+
+	location *time.Location
+}
+
+// ScheduledPhase returns the phase of the instrument schedule at the given
+// time or an empty phase if it has no schedule.
+func (i *Instrument) ScheduledPhase(now time.Time) Phase {
+	return schedulePhase(i.Schedule, i.location, now)
+}
+
+// Phase returns the trading phase implied by the status of the instrument or
+// an empty phase if the status does not imply any.
+func (i *Instrument) Phase() Phase {
+	switch i.TradingStatus() {
+	case enum.SecurityTradingStatus_TRADING_HALT:
+		return PhaseHalted
+	case enum.SecurityTradingStatus_PRE_OPEN:
+		return PhasePreOpen
+	case enum.SecurityTradingStatus_OPENING_ROTATION:
+		return PhaseOpeningAuction
+	case enum.SecurityTradingStatus_NO_OPEN_NO_RESUME,
+		enum.SecurityTradingStatus_NOT_AVAILABLE_FOR_TRADING,
+		enum.SecurityTradingStatus_POST_CLOSE:
+		return PhaseClosed
+	}
+	return ""
+}
+
+// TradingStatus returns the FIX security trading status of the instrument,
+// instruments without status are ready to trade.
+func (i *Instrument) TradingStatus() enum.SecurityTradingStatus {
+	if len(i.Status) == 0 {
+		return enum.SecurityTradingStatus_READY_TO_TRADE
+	}
+	return dict.SecurityTradingStatuses[i.Status]
+}
+
+type TradingSession struct {
+	TradingSessionID    string `yaml:"TradingSessionID"`
+	TradingSessionSubID string `yaml:"TradingSessionSubID"`
+	Status              string `yaml:"Status"`
+
+	// Schedule gives the phases of the trading session through the day in
+	// TimeZone, UTC by default. Sessions without schedule stay in the phase
+	// of their status.
+	Schedule []*PhaseChange `yaml:"Schedule"`
+	TimeZone string         `yaml:"TimeZone"`
+
+	location *time.Location
+}
+
+// Phase returns the phase of the trading session at the given time.
+func (s *TradingSession) Phase(now time.Time) Phase {
+	if phase := schedulePhase(s.Schedule, s.location, now); len(phase) > 0 {
+		return phase
+	}
+
+	switch s.TradSesStatus() {
+	case enum.TradSesStatus_HALTED:
+		return PhaseHalted
+	case enum.TradSesStatus_CLOSED:
+		return PhaseClosed
+	case enum.TradSesStatus_PRE_OPEN:
+		return PhasePreOpen
+	}
+	return PhaseContinuous
+}
+
+// TradSesStatus returns the FIX status of the trading session, sessions
+// without status are open.
+func (s *TradingSession) TradSesStatus() enum.TradSesStatus {
+	if len(s.Status) == 0 {
+		return enum.TradSesStatus_OPEN
+	}
+	return dict.TradSesStatuses[s.Status]
+}
+
+// Load reads the reference data from a YAML file.
+func Load(path string) (*Data, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data := Data{}
+	decoder := yaml.NewDecoder(bytes.NewBuffer(file))
+	decoder.KnownFields(true)
+
+	if err = decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	if err = data.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (d *Data) Validate() error {
+	symbols := make(map[string]bool, len(d.Instruments))
+	for _, instrument := range d.Instruments {
+		if symbols[instrument.Symbol] {
+			return fmt.Errorf("%w: %s", errors.RefDataDuplicateSymbol, instrument.Symbol)
+		}
+		symbols[instrument.Symbol] = true
+
+		if _, ok := dict.SecurityTradingStatuses[instrument.Status]; len(instrument.Status) > 0 && !ok {
+			return fmt.Errorf("%w: %s", errors.RefDataUnknownStatus, instrument.Status)
+		}
+
+		location, err := validateSchedule(instrument.Schedule, instrument.TimeZone)
+		if err != nil {
+			return fmt.Errorf("instrument %s: %w", instrument.Symbol, err)
+		}
+		instrument.location = location
+	}
+
+	for _, session := range d.TradingSessions {
+		if _, ok := dict.TradSesStatuses[session.Status]; len(session.Status) > 0 && !ok {
+			return fmt.Errorf("%w: %s", errors.RefDataUnknownStatus, session.Status)
+		}
+		location, err := validateSchedule(session.Schedule, session.TimeZone)
+		if err != nil {
+			return fmt.Errorf("trading session %s: %w", session.TradingSessionID, err)
+		}
+		session.location = location
+	}
+
+	return nil
+}
+
+// Instrument returns the instrument identified by symbol or nil if unknown.
+func (d *Data) Instrument(symbol string) *Instrument {
+	for _, instrument := range d.Instruments {
+		if instrument.Symbol == symbol {
+			return instrument
+		}
+	}
+	return nil
+}
+
+// TradingSession returns the trading session identified by id or nil if
+// unknown.
+func (d *Data) TradingSession(id string) *TradingSession {
+	for _, session := range d.TradingSessions {
+		if session.TradingSessionID == id {
+			return session
+		}
+	}
+	return nil
+}
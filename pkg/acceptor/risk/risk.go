@@ -0,0 +1,226 @@
+package risk
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/shopspring/decimal"
+
+	yaml "sylr.dev/yaml/v3"
+
+	"sylr.dev/fix/pkg/acceptor/engine"
+	"sylr.dev/fix/pkg/errors"
+)
+
+var (
+	metricRiskOrdersChecked = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "fix",
+			Subsystem: "acceptor_risk",
+			Name:      "orders_checked_total",
+			Help:      "Number of orders checked against risk limits",
+		},
+		[]string{"session", "sender_comp_id"},
+	)
+	metricRiskRejects = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "fix",
+			Subsystem: "acceptor_risk",
+			Name:      "rejects_total",
+			Help:      "Number of orders rejected by risk limits",
+		},
+		[]string{"session", "sender_comp_id", "limit"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(metricRiskOrdersChecked)
+	prometheus.MustRegister(metricRiskRejects)
+}
+
+// Config holds the pre-trade limits of the acceptor. Limits of a session take
+// precedence over the ones of its SenderCompID which take precedence over the
+// default ones.
+type Config struct {
+	Default       *Limits            `yaml:"default"`
+	Sessions      map[string]*Limits `yaml:"sessions"`
+	SenderCompIDs map[string]*Limits `yaml:"sender-comp-ids"`
+}
+
+// Limits are pre-trade limits, zero values disable the matching check.
+type Limits struct {
+	MaxOrderQty   decimal.Decimal `yaml:"max-order-qty"`
+	MaxNotional   decimal.Decimal `yaml:"max-notional"`
+	PriceCollar   decimal.Decimal `yaml:"price-collar"`
+	Symbols       []string        `yaml:"symbols"`
+	MaxOpenOrders int             `yaml:"max-open-orders"`
+}
+
+// Violation is a limit breached by an order.
+type Violation struct {
+	Limit  string
+	Reason enum.OrdRejReason
+	Err    error
+}
+
+func (v *Violation) Error() string {
+	return v.Err.Error()
+}
+
+func (v *Violation) Unwrap() error {
+	return v.Err
+}
+
+// Load reads the risk limits from a YAML file.
+func Load(path string) (*Config, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := Config{}
+	decoder := yaml.NewDecoder(bytes.NewBuffer(file))
+	decoder.KnownFields(true)
+
+	if err = decoder.Decode(&config); err != nil {
+		return nil, err
+	}
+
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func (c *Config) Validate() error {
+	all := map[string]*Limits{"default": c.Default}
+	for id, limits := range c.Sessions {
+		all[id] = limits
+	}
+	for id, limits := range c.SenderCompIDs {
+		all[id] = limits
+	}
+
+	for id, limits := range all {
+		if limits == nil {
+			continue
+		}
+		if limits.MaxOrderQty.IsNegative() || limits.MaxNotional.IsNegative() || limits.PriceCollar.IsNegative() || limits.MaxOpenOrders < 0 {
+			return fmt.Errorf("%w: negative limit for %s", errors.RiskInvalidLimit, id)
+		}
+		for _, symbol := range limits.Symbols {
+			if _, err := path.Match(symbol, ""); err != nil {
+				return fmt.Errorf("%w: invalid symbol pattern %s for %s", errors.RiskInvalidLimit, symbol, id)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Limits returns the limits applying to the session or nil. bySenderCompID is
+// true when the limits are the ones of the session SenderCompID, open orders
+// are then counted across all its sessions.
+func (c *Config) Limits(sessionID quickfix.SessionID) (limits *Limits, bySenderCompID bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	// On the acceptor side, the counterparty SenderCompID is the session target
+	if limits, ok := c.Sessions[sessionID.String()]; ok {
+		return limits, false
+	}
+	if limits, ok := c.SenderCompIDs[sessionID.TargetCompID]; ok {
+		return limits, true
+	}
+
+	return c.Default, false
+}
+
+// Check returns the first limit breached by the order. reference is the
+// price used by the price collar and to value orders without a limit price,
+// stop orders waiting for their trigger are valued at their stop price, it is
+// ignored if zero. The price collar only applies to limit prices. openOrders
+// is the number of orders already open in the limits scope.
+func (l *Limits) Check(order *engine.Order, reference decimal.Decimal, openOrders int) *Violation {
+	if l == nil {
+		return nil
+	}
+
+	if len(l.Symbols) > 0 && !l.allows(order.Symbol) {
+		return &Violation{
+			Limit:  "symbols",
+			Reason: enum.OrdRejReason_UNKNOWN_SYMBOL,
+			Err:    fmt.Errorf("%w: %s", errors.RiskSymbolNotAllowed, order.Symbol),
+		}
+	}
+
+	if l.MaxOrderQty.IsPositive() && order.Quantity.GreaterThan(l.MaxOrderQty) {
+		return &Violation{
+			Limit:  "max-order-qty",
+			Reason: enum.OrdRejReason_ORDER_EXCEEDS_LIMIT,
+			Err:    fmt.Errorf("%w: %s > %s", errors.RiskMaxOrderQty, order.Quantity, l.MaxOrderQty),
+		}
+	}
+
+	price := order.Price
+	if !order.HasPrice() {
+		price = reference
+		if order.IsArmed() && order.StopPx.IsPositive() {
+			price = order.StopPx
+		}
+	}
+
+	if notional := price.Mul(order.Quantity); l.MaxNotional.IsPositive() && notional.GreaterThan(l.MaxNotional) {
+		return &Violation{
+			Limit:  "max-notional",
+			Reason: enum.OrdRejReason_ORDER_EXCEEDS_LIMIT,
+			Err:    fmt.Errorf("%w: %s > %s", errors.RiskMaxNotional, notional, l.MaxNotional),
+		}
+	}
+
+	if l.PriceCollar.IsPositive() && reference.IsPositive() && order.HasPrice() {
+		band := reference.Mul(l.PriceCollar).Div(decimal.NewFromInt(100))
+		low, high := reference.Sub(band), reference.Add(band)
+		if order.Price.LessThan(low) || order.Price.GreaterThan(high) {
+			return &Violation{
+				Limit:  "price-collar",
+				Reason: enum.OrdRejReason_PRICE_EXCEEDS_CURRENT_PRICE_BAND,
+				Err:    fmt.Errorf("%w: %s not in [%s, %s]", errors.RiskPriceCollar, order.Price, low, high),
+			}
+		}
+	}
+
+	if l.MaxOpenOrders > 0 && openOrders >= l.MaxOpenOrders {
+		return &Violation{
+			Limit:  "max-open-orders",
+			Reason: enum.OrdRejReason_ORDER_EXCEEDS_LIMIT,
+			Err:    fmt.Errorf("%w: %d", errors.RiskMaxOpenOrders, l.MaxOpenOrders),
+		}
+	}
+
+	return nil
+}
+
+func (l *Limits) allows(symbol string) bool {
+	for _, pattern := range l.Symbols {
+		if ok, _ := path.Match(pattern, symbol); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Record updates the risk metrics with the outcome of an order check.
+func Record(sessionID quickfix.SessionID, violation *Violation) {
+	metricRiskOrdersChecked.WithLabelValues(sessionID.String(), sessionID.TargetCompID).Inc()
+	if violation != nil {
+		metricRiskRejects.WithLabelValues(sessionID.String(), sessionID.TargetCompID, violation.Limit).Inc()
+	}
+}
@@ -0,0 +1,118 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/shopspring/decimal"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// Two characters operators first so that ">=" is not parsed as ">".
+var operators = []string{">=", "<=", "!=", "=", ">", "<"}
+
+// predicate compares the value of a message field with a value. Equality
+// operators accept glob patterns, ordering operators compare numbers.
+type predicate struct {
+	tag      quickfix.Tag
+	operator string
+	value    string
+}
+
+func parsePredicate(raw string, dd *datadictionary.DataDictionary) (*predicate, error) {
+	// The operator is the first one found in the predicate so that values
+	// can contain operator characters.
+	i := strings.IndexAny(raw, "<>=!")
+	for _, op := range operators {
+		if i <= 0 || !strings.HasPrefix(raw[i:], op) {
+			continue
+		}
+
+		p := predicate{
+			operator: op,
+			value:    strings.TrimSpace(raw[i+len(op):]),
+		}
+
+		name := strings.TrimSpace(raw[:i])
+		if t, err := strconv.Atoi(name); err == nil {
+			p.tag = quickfix.Tag(t)
+		} else if dd != nil && dd.FieldTypeByName[name] != nil {
+			p.tag = quickfix.Tag(dd.FieldTypeByName[name].Tag())
+		} else {
+			return nil, fmt.Errorf("%w: %s", errors.RulesUnknownField, name)
+		}
+
+		switch op {
+		case "=", "!=":
+			if _, err := path.Match(p.value, ""); err != nil {
+				return nil, fmt.Errorf("%w: %s", errors.RulesInvalidPredicate, raw)
+			}
+		default:
+			if _, err := decimal.NewFromString(p.value); err != nil {
+				return nil, fmt.Errorf("%w: %s", errors.RulesInvalidPredicate, raw)
+			}
+		}
+
+		return &p, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", errors.RulesInvalidPredicate, raw)
+}
+
+// match returns false if the field is neither in the body nor in the header
+// of the message.
+func (p *predicate) match(msg *quickfix.Message) bool {
+	var value string
+	var err error
+
+	switch {
+	case msg.Body.Has(p.tag):
+		value, err = msg.Body.GetString(p.tag)
+	case msg.Header.Has(p.tag):
+		value, err = msg.Header.GetString(p.tag)
+	default:
+		return false
+	}
+
+	if err != nil {
+		return false
+	}
+
+	return p.matchValue(value)
+}
+
+func (p *predicate) matchValue(value string) bool {
+	switch p.operator {
+	case "=":
+		ok, _ := path.Match(p.value, value)
+		return ok
+	case "!=":
+		ok, _ := path.Match(p.value, value)
+		return !ok
+	}
+
+	actual, err := decimal.NewFromString(value)
+	if err != nil {
+		return false
+	}
+
+	expected := decimal.RequireFromString(p.value)
+
+	switch p.operator {
+	case ">":
+		return actual.GreaterThan(expected)
+	case ">=":
+		return actual.GreaterThanOrEqual(expected)
+	case "<":
+		return actual.LessThan(expected)
+	case "<=":
+		return actual.LessThanOrEqual(expected)
+	}
+
+	return false
+}
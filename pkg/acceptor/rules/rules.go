@@ -0,0 +1,189 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/tag"
+
+	yaml "sylr.dev/yaml/v3"
+
+	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/errors"
+)
+
+type Action string
+
+const (
+	// ActionAccept processes the message as usual, it is only useful with a
+	// delay.
+	ActionAccept Action = "accept"
+	// ActionReject rejects orders with the rule OrdRejReason, other messages
+	// get a BusinessMessageReject.
+	ActionReject Action = "reject"
+	// ActionPartialFill accepts orders and fills the rule FillPercent of them
+	// at their limit price, its predicates must exclude market orders.
+	ActionPartialFill Action = "partial-fill"
+	// ActionDrop silently ignores the message.
+	ActionDrop Action = "drop"
+	// ActionBusinessReject answers with a BusinessMessageReject.
+	ActionBusinessReject Action = "business-reject"
+)
+
+// Rules is an ordered list of rules, the first rule matching a message
+// decides how the acceptor responds to it.
+type Rules struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+type Rule struct {
+	Name                 string        `yaml:"name"`
+	MsgType              string        `yaml:"MsgType"`
+	Match                []string      `yaml:"match"`
+	Action               Action        `yaml:"action"`
+	Delay                time.Duration `yaml:"delay"`
+	FillPercent          int           `yaml:"fill-percent"`
+	OrdRejReason         string        `yaml:"OrdRejReason"`
+	BusinessRejectReason string        `yaml:"BusinessRejectReason"`
+	Text                 string        `yaml:"Text"`
+
+	predicates []*predicate
+}
+
+// Load reads rules from a YAML file. Field names used in predicates are
+// resolved with the given data dictionary, tag numbers can be used as well.
+func Load(path string, dd *datadictionary.DataDictionary) (*Rules, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := Rules{}
+	decoder := yaml.NewDecoder(bytes.NewBuffer(file))
+	decoder.KnownFields(true)
+
+	if err = decoder.Decode(&rules); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules.Rules {
+		if err = rule.compile(dd); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+	}
+
+	return &rules, nil
+}
+
+// Match returns the first rule matching the message or nil.
+func (r *Rules) Match(msg *quickfix.Message) *Rule {
+	if r == nil {
+		return nil
+	}
+
+	for _, rule := range r.Rules {
+		if rule.match(msg) {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+func (r *Rule) compile(dd *datadictionary.DataDictionary) error {
+	if len(r.Action) == 0 {
+		r.Action = ActionAccept
+	}
+
+	switch r.Action {
+	case ActionAccept, ActionDrop:
+	case ActionReject, ActionBusinessReject:
+		if _, ok := dict.OrdRejReasons[r.OrdRejReason]; len(r.OrdRejReason) > 0 && !ok {
+			return fmt.Errorf("%w: %s", errors.RulesUnknownReason, r.OrdRejReason)
+		}
+		if _, ok := dict.BusinessRejectReasons[r.BusinessRejectReason]; len(r.BusinessRejectReason) > 0 && !ok {
+			return fmt.Errorf("%w: %s", errors.RulesUnknownReason, r.BusinessRejectReason)
+		}
+	case ActionPartialFill:
+		if r.FillPercent <= 0 || r.FillPercent > 100 {
+			return fmt.Errorf("%w: %d", errors.RulesInvalidFillPercent, r.FillPercent)
+		}
+	default:
+		return fmt.Errorf("%w: %s", errors.RulesUnknownAction, r.Action)
+	}
+
+	r.predicates = make([]*predicate, 0, len(r.Match))
+	for _, raw := range r.Match {
+		p, err := parsePredicate(raw, dd)
+		if err != nil {
+			return err
+		}
+		r.predicates = append(r.predicates, p)
+	}
+
+	// Market orders have no limit price to be filled at.
+	if r.Action == ActionPartialFill && !r.excludesMarketOrders() {
+		return errors.RulesPartialFillMarket
+	}
+
+	return nil
+}
+
+// excludesMarketOrders returns true if one of the predicates of the rule
+// does not match market orders.
+func (r *Rule) excludesMarketOrders() bool {
+	for _, p := range r.predicates {
+		if p.tag == tag.OrdType && !p.matchValue(string(enum.OrdType_MARKET)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rule) match(msg *quickfix.Message) bool {
+	if len(r.MsgType) > 0 {
+		if msgType, err := msg.MsgType(); err != nil || msgType != r.MsgType {
+			return false
+		}
+	}
+
+	for _, p := range r.predicates {
+		if !p.match(msg) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetOrdRejReason returns the reason used to reject orders, it defaults to
+// OTHER.
+func (r *Rule) GetOrdRejReason() enum.OrdRejReason {
+	if reason, ok := dict.OrdRejReasons[r.OrdRejReason]; ok {
+		return reason
+	}
+	return enum.OrdRejReason_OTHER
+}
+
+// GetBusinessRejectReason returns the reason used in business message
+// rejects, it defaults to OTHER.
+func (r *Rule) GetBusinessRejectReason() enum.BusinessRejectReason {
+	if reason, ok := dict.BusinessRejectReasons[r.BusinessRejectReason]; ok {
+		return reason
+	}
+	return enum.BusinessRejectReason_OTHER
+}
+
+// GetText returns the text sent along rejects.
+func (r *Rule) GetText() string {
+	if len(r.Text) > 0 {
+		return r.Text
+	}
+	return fmt.Sprintf("rule %s", r.Name)
+}
@@ -0,0 +1,91 @@
+package sink
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"text/template"
+)
+
+// File appends messages as JSON lines to files whose names are rendered from
+// its template. Files are opened on first use.
+type File struct {
+	name  *template.Template
+	files map[string]*os.File
+	mux   sync.Mutex
+}
+
+func NewFile(name *template.Template) *File {
+	return &File{
+		name:  name,
+		files: make(map[string]*os.File),
+	}
+}
+
+func (s *File) Send(msg *Message) error {
+	name, err := render(s.name, msg)
+	if err != nil {
+		return err
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	file, ok := s.files[name]
+	if !ok {
+		file, err = os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		s.files[name] = file
+	}
+
+	return writeJSONLine(file, msg)
+}
+
+func (s *File) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var err error
+	for name, file := range s.files {
+		if cerr := file.Close(); cerr != nil {
+			err = cerr
+		}
+		delete(s.files, name)
+	}
+
+	return err
+}
+
+// Stdout writes messages as JSON lines on the standard output.
+type Stdout struct {
+	mux sync.Mutex
+}
+
+func NewStdout() *Stdout {
+	return &Stdout{}
+}
+
+func (s *Stdout) Send(msg *Message) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return writeJSONLine(os.Stdout, msg)
+}
+
+func (s *Stdout) Close() error {
+	return nil
+}
+
+func writeJSONLine(w io.Writer, msg *Message) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(line, '\n'))
+
+	return err
+}
@@ -0,0 +1,120 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// httpQueueSize is the number of messages waiting to be posted beyond which
+// the HTTP sink fails.
+const httpQueueSize = 1024
+
+// HTTP posts messages as JSON documents to a webhook whose URL is rendered
+// from its template. Messages are posted in the background from a bounded
+// queue so that a slow webhook does not hold the sessions, post failures are
+// logged. Sending only fails when the queue is full or the sink closed, a
+// required HTTP sink thus only guarantees that messages are queued.
+type HTTP struct {
+	url    *template.Template
+	client *http.Client
+	logger *zerolog.Logger
+	queue  chan httpPost
+	done   chan struct{}
+	closed bool
+	mux    sync.RWMutex
+}
+
+type httpPost struct {
+	url     string
+	msgType string
+	body    []byte
+}
+
+func NewHTTP(url *template.Template, logger *zerolog.Logger) *HTTP {
+	s := &HTTP{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+		logger: logger,
+		queue:  make(chan httpPost, httpQueueSize),
+		done:   make(chan struct{}),
+	}
+
+	go s.run()
+
+	return s
+}
+
+func (s *HTTP) Send(msg *Message) error {
+	url, err := render(s.url, msg)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	if s.closed {
+		return errors.SinkClosed
+	}
+
+	select {
+	case s.queue <- httpPost{url: url, msgType: msg.MsgType, body: body}:
+		return nil
+	default:
+		return fmt.Errorf("%w: %d messages waiting", errors.SinkHTTPQueueFull, httpQueueSize)
+	}
+}
+
+func (s *HTTP) run() {
+	defer close(s.done)
+
+	for p := range s.queue {
+		if err := s.post(p); err != nil {
+			s.logger.Error().Err(err).Msgf("Unable to post message %s", p.msgType)
+		}
+	}
+}
+
+func (s *HTTP) post(p httpPost) error {
+	resp, err := s.client.Post(p.url, "application/json", bytes.NewBuffer(p.body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: %s returned %s", errors.SinkHTTPStatus, p.url, resp.Status)
+	}
+
+	return nil
+}
+
+// Close posts the messages left in the queue before returning.
+func (s *HTTP) Close() error {
+	s.mux.Lock()
+	if s.closed {
+		s.mux.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mux.Unlock()
+
+	<-s.done
+	s.client.CloseIdleConnections()
+	return nil
+}
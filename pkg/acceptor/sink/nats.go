@@ -0,0 +1,59 @@
+package sink
+
+import (
+	"text/template"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSSessionIDHeader is the NATS header holding the FIX session of the
+// published messages.
+const NATSSessionIDHeader = "Fix-Session-ID"
+
+// NATS publishes the raw FIX messages on a subject rendered from its template.
+type NATS struct {
+	conn    *nats.Conn
+	subject *template.Template
+	owned   bool
+}
+
+// NewNATS returns a sink publishing with an existing connection which is not
+// closed along with the sink.
+func NewNATS(conn *nats.Conn, subject *template.Template) *NATS {
+	return &NATS{conn: conn, subject: subject}
+}
+
+// NewNATSFromURL returns a sink with its own connection to the NATS server.
+func NewNATSFromURL(url string, subject *template.Template) (*NATS, error) {
+	natsOptions := []nats.Option{
+		nats.DontRandomize(),
+		nats.RetryOnFailedConnect(true),
+	}
+
+	conn, err := nats.Connect(url, natsOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NATS{conn: conn, subject: subject, owned: true}, nil
+}
+
+func (s *NATS) Send(msg *Message) error {
+	subject, err := render(s.subject, msg)
+	if err != nil {
+		return err
+	}
+
+	natsMsg := nats.NewMsg(subject)
+	natsMsg.Header.Set(NATSSessionIDHeader, msg.SessionID)
+	natsMsg.Data = []byte(msg.Message)
+
+	return s.conn.PublishMsg(natsMsg)
+}
+
+func (s *NATS) Close() error {
+	if s.owned {
+		s.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package sink
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+	"github.com/rs/zerolog"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/utils"
+)
+
+// Sink is a destination for the messages received by the acceptor.
+type Sink interface {
+	Send(msg *Message) error
+	Close() error
+}
+
+// Message is a FIX message received by the acceptor as given to sinks. Its
+// fields can be used in sink templates and it is the JSON document written by
+// the JSONL, stdout, HTTP and Unix socket sinks.
+type Message struct {
+	Time      time.Time `json:"time"`
+	SessionID string    `json:"session_id"`
+	MsgType   string    `json:"msg_type"`
+	Symbol    string    `json:"symbol,omitempty"`
+	Side      string    `json:"side,omitempty"`
+	Type      string    `json:"type,omitempty"`
+	Message   string    `json:"message"`
+}
+
+// NewMessage extracts the sink message from a FIX message.
+func NewMessage(msg *quickfix.Message, sessionID quickfix.SessionID) *Message {
+	side, _ := dict.Search(dict.OrderSides, utils.MustNot(msg.Body.GetString(tag.Side)))
+	ordType, _ := dict.Search(dict.OrderTypes, utils.MustNot(msg.Body.GetString(tag.OrdType)))
+
+	return &Message{
+		Time:      time.Now(),
+		SessionID: sessionID.String(),
+		MsgType:   utils.MustNot(msg.MsgType()),
+		Symbol:    utils.MustNot(msg.Body.GetString(tag.Symbol)),
+		Side:      string(side),
+		Type:      string(ordType),
+		Message:   msg.String(),
+	}
+}
+
+// New builds a sink from its configuration. Only messages of the configured
+// types, orders by default, are given to it.
+func New(conf *config.Sink, logger *zerolog.Logger) (Sink, error) {
+	tpl, err := template.New(conf.Type).Parse(conf.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Sink
+	switch conf.Type {
+	case "nats":
+		s, err = NewNATSFromURL(conf.URL, tpl)
+	case "file":
+		s = NewFile(tpl)
+	case "stdout":
+		s = NewStdout()
+	case "http":
+		s = NewHTTP(tpl, logger)
+	case "unix":
+		s = NewUnix(tpl)
+	default:
+		return nil, fmt.Errorf("%w: %s", errors.SinkUnknownType, conf.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	msgTypes := conf.MsgTypes
+	if len(msgTypes) == 0 {
+		msgTypes = []string{"D"}
+	}
+
+	s = Filter(s, msgTypes...)
+	if conf.Required {
+		s = Require(s)
+	}
+
+	return s, nil
+}
+
+func render(tpl *template.Template, msg *Message) (string, error) {
+	buf := bytes.NewBuffer([]byte{})
+	if err := tpl.Execute(buf, msg); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+type filter struct {
+	Sink
+	msgTypes []string
+}
+
+// Filter wraps the sink so that it only gets messages of the given types.
+func Filter(s Sink, msgTypes ...string) Sink {
+	return &filter{Sink: s, msgTypes: msgTypes}
+}
+
+func (f *filter) Send(msg *Message) error {
+	if utils.Search(f.msgTypes, msg.MsgType) < 0 {
+		return nil
+	}
+	return f.Sink.Send(msg)
+}
+
+type required struct {
+	Sink
+}
+
+// Require marks the sink as required, the acceptor rejects the messages it
+// fails to take.
+func Require(s Sink) Sink {
+	return &required{Sink: s}
+}
+
+// IsRequired tells whether the messages the sink fails to take are rejected.
+func IsRequired(s Sink) bool {
+	_, ok := s.(*required)
+	return ok
+}
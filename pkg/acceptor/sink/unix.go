@@ -0,0 +1,64 @@
+package sink
+
+import (
+	"net"
+	"sync"
+	"text/template"
+)
+
+// Unix writes messages as JSON lines to Unix sockets whose paths are rendered
+// from its template. Connections are made on first use and made again after a
+// write failure.
+type Unix struct {
+	path  *template.Template
+	conns map[string]net.Conn
+	mux   sync.Mutex
+}
+
+func NewUnix(path *template.Template) *Unix {
+	return &Unix{
+		path:  path,
+		conns: make(map[string]net.Conn),
+	}
+}
+
+func (s *Unix) Send(msg *Message) error {
+	path, err := render(s.path, msg)
+	if err != nil {
+		return err
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	conn, ok := s.conns[path]
+	if !ok {
+		conn, err = net.Dial("unix", path)
+		if err != nil {
+			return err
+		}
+		s.conns[path] = conn
+	}
+
+	if err = writeJSONLine(conn, msg); err != nil {
+		conn.Close()
+		delete(s.conns, path)
+	}
+
+	return err
+}
+
+func (s *Unix) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var err error
+	for path, conn := range s.conns {
+		if cerr := conn.Close(); cerr != nil {
+			err = cerr
+		}
+		delete(s.conns, path)
+	}
+
+	return err
+}
@@ -0,0 +1,20 @@
+// Package admin holds the handlers of the admin API served by InitHTTP under
+// /admin/. Commands register their handlers when they start.
+package admin
+
+import (
+	"net/http"
+)
+
+var mux = http.NewServeMux()
+
+// Handle registers the handler for the given pattern, patterns must start with
+// /admin/.
+func Handle(pattern string, handler http.Handler) {
+	mux.Handle(pattern, handler)
+}
+
+// Handler returns the handler serving the registered admin handlers.
+func Handler() http.Handler {
+	return mux
+}
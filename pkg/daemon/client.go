@@ -0,0 +1,155 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/tag"
+	"github.com/rs/zerolog"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/errors"
+)
+
+// Client sends messages through a running daemon instead of initiating the
+// sessions itself.
+type Client struct {
+	logger        *zerolog.Logger
+	conn          net.Conn
+	encoder       *json.Encoder
+	transportDict *datadictionary.DataDictionary
+	appDict       *datadictionary.DataDictionary
+	acks          chan ack
+	messages      chan *quickfix.Message
+	done          chan struct{}
+	closeOnce     sync.Once
+	mux           sync.Mutex
+}
+
+// Dial connects to the daemon listening on the socket, it fails straight away
+// when no daemon is running. Received messages are parsed with the given
+// dictionaries.
+func Dial(logger *zerolog.Logger, socket string, transportDict, appDict *datadictionary.DataDictionary) (*Client, error) {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+
+	c := Client{
+		logger:        logger,
+		conn:          conn,
+		encoder:       json.NewEncoder(conn),
+		transportDict: transportDict,
+		appDict:       appDict,
+		acks:          make(chan ack),
+		messages:      make(chan *quickfix.Message, 16),
+		done:          make(chan struct{}),
+	}
+
+	go c.read()
+
+	return &c, nil
+}
+
+// Close disconnects from the daemon, the sessions stay logged on.
+func (c *Client) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = c.conn.Close()
+	})
+
+	return err
+}
+
+// Messages returns the channel of the messages received on the sessions the
+// client sent messages on, it is closed when the daemon disconnects.
+func (c *Client) Messages() <-chan *quickfix.Message {
+	return c.messages
+}
+
+// ack is the daemon answer to a request.
+type ack struct {
+	seqNum int
+	err    error
+}
+
+// Send sends the message on the session of the context and waits for the
+// daemon to acknowledge it. The header of the message is given the sequence
+// number it was sent with, as when sending on a session.
+func (c *Client) Send(context string, session config.Session, m quickfix.Messagable) error {
+	msg := m.ToMessage()
+	msg.Header.SetField(tag.BeginString, quickfix.FIXString(session.BeginString))
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	err := c.encoder.Encode(Request{
+		Context: context,
+		Session: session.Name,
+		Message: msg.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	a, ok := <-c.acks
+	if !ok {
+		return io.ErrUnexpectedEOF
+	}
+
+	if a.err == nil && a.seqNum > 0 {
+		msg.Header.SetInt(tag.MsgSeqNum, a.seqNum)
+	}
+
+	return a.err
+}
+
+func (c *Client) read() {
+	defer close(c.messages)
+	defer close(c.acks)
+
+	decoder := json.NewDecoder(c.conn)
+	for {
+		response := Response{}
+		if err := decoder.Decode(&response); err != nil {
+			return
+		}
+
+		switch {
+		case len(response.Error) > 0:
+			c.ack(ack{err: fmt.Errorf("%w: %s", errors.Daemon, strings.TrimPrefix(response.Error, errors.Daemon.Error()+": "))})
+
+		case response.Sent:
+			c.ack(ack{seqNum: response.SeqNum})
+
+		case len(response.Message) > 0:
+			msg := quickfix.NewMessage()
+			err := quickfix.ParseMessageWithDataDictionary(msg, bytes.NewBufferString(response.Message), c.transportDict, c.appDict)
+			if err != nil {
+				c.logger.Error().Err(err).Msg("Unable to parse message relayed by daemon")
+				continue
+			}
+
+			select {
+			case c.messages <- msg:
+			case <-c.done:
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) ack(a ack) {
+	select {
+	case c.acks <- a:
+	case <-c.done:
+	}
+}
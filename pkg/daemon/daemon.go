@@ -0,0 +1,43 @@
+package daemon
+
+import (
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+)
+
+// Request asks the daemon to send a message on a session, requests and
+// responses are JSON documents written one per line on the socket.
+type Request struct {
+	Context string `json:"context"`
+	Session string `json:"session"`
+
+	// Message is the raw FIX message, its header must carry the BeginString.
+	Message string `json:"message"`
+}
+
+// Response either acknowledges a request, with Sent or Error set, or relays
+// a message received on a session the client sent messages on. SeqNum is the
+// sequence number the message of an acknowledged request was sent with.
+type Response struct {
+	Sent    bool   `json:"sent,omitempty"`
+	SeqNum  int    `json:"seq_num,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Sessions are shared by all the clients of the daemon: a client is only
+// relayed the messages referencing an identifier it sent, and the session
+// level rejects referencing the sequence number of one of its messages.
+var (
+	sentCorrelationTags = []quickfix.Tag{
+		tag.ClOrdID,
+		tag.OrigClOrdID,
+		tag.MDReqID,
+		tag.QuoteReqID,
+		tag.QuoteID,
+		tag.SecurityReqID,
+		tag.SecurityStatusReqID,
+		tag.TradSesReqID,
+	}
+	receivedCorrelationTags = append([]quickfix.Tag{tag.BusinessRejectRefID}, sentCorrelationTags...)
+)
@@ -0,0 +1,349 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/tag"
+	"github.com/rs/zerolog"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/initiator"
+	"sylr.dev/fix/pkg/initiator/application"
+)
+
+type session struct {
+	app           *application.Daemon
+	id            quickfix.SessionID
+	transportDict *datadictionary.DataDictionary
+	appDict       *datadictionary.DataDictionary
+}
+
+// Server owns the initiators of the contexts and sends the messages of its
+// clients on their sessions.
+type Server struct {
+	Logger *zerolog.Logger
+
+	// sessions are indexed by context and session names.
+	sessions   map[string]*session
+	initiators []*quickfix.Initiator
+	listener   net.Listener
+	wg         sync.WaitGroup
+}
+
+func sessionKey(context, session string) string {
+	return context + "/" + session
+}
+
+// NewServer prepares an initiator for each context having one. A session
+// shared by several contexts is only initiated by the first of them.
+func NewServer(logger *zerolog.Logger, contexts []*config.Context, quickfixLogger *zerolog.Logger) (*Server, error) {
+	s := Server{
+		Logger:   logger,
+		sessions: make(map[string]*session),
+	}
+
+	owned := make(map[string]*session)
+
+	for _, context := range contexts {
+		if len(context.Initiator) == 0 {
+			continue
+		}
+
+		sessions, err := context.GetSessions()
+		if err != nil {
+			return nil, err
+		}
+
+		var names []string
+		for _, sess := range sessions {
+			if _, ok := owned[sess.Name]; !ok {
+				names = append(names, sess.Name)
+			}
+		}
+
+		if len(names) > 0 {
+			settings, err := context.ToQuickFixInitiatorSettings(names...)
+			if err != nil {
+				return nil, err
+			}
+
+			app := application.NewDaemon()
+			app.Logger = logger
+			app.Settings = settings
+
+			init, err := initiator.Initiate(app, settings, quickfixLogger)
+			if err != nil {
+				return nil, err
+			}
+
+			s.initiators = append(s.initiators, init)
+
+			for _, sess := range sessions {
+				if _, ok := owned[sess.Name]; ok {
+					continue
+				}
+
+				transportDict, appDict, err := sess.GetFIXDictionaries()
+				if err != nil {
+					return nil, err
+				}
+
+				owned[sess.Name] = &session{
+					app:           app,
+					id:            sess.SessionID(),
+					transportDict: transportDict,
+					appDict:       appDict,
+				}
+			}
+		}
+
+		for _, sess := range sessions {
+			s.sessions[sessionKey(context.Name, sess.Name)] = owned[sess.Name]
+		}
+	}
+
+	return &s, nil
+}
+
+// Start starts the initiators and listens on the socket. It fails if another
+// daemon already listens on it, a socket left behind by a crashed daemon is
+// removed.
+func (s *Server) Start(socket string) error {
+	if conn, err := net.Dial("unix", socket); err == nil {
+		conn.Close()
+		return fmt.Errorf("%w: %s", errors.DaemonAlreadyRunning, socket)
+	}
+
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(socket), 0700); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+
+	for i, init := range s.initiators {
+		if err := init.Start(); err != nil {
+			for _, started := range s.initiators[:i] {
+				started.Stop()
+			}
+			listener.Close()
+			return err
+		}
+	}
+
+	s.listener = listener
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return nil
+}
+
+// Stop stops accepting clients and logs the sessions out.
+func (s *Server) Stop() {
+	if s.listener != nil {
+		s.listener.Close()
+	}
+
+	s.wg.Wait()
+
+	for _, init := range s.initiators {
+		init.Stop()
+	}
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.Logger.Error().Err(err).Msg("Unable to accept client")
+			}
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+// client is a connection to the daemon.
+type client struct {
+	server        *Server
+	responses     chan Response
+	done          chan struct{}
+	ids           map[string]struct{}
+	seqNums       map[*session]map[int]struct{}
+	subscriptions map[*session]chan *quickfix.Message
+	mux           sync.RWMutex
+}
+
+func (s *Server) handle(conn net.Conn) {
+	c := client{
+		server:        s,
+		responses:     make(chan Response, 16),
+		done:          make(chan struct{}),
+		ids:           make(map[string]struct{}),
+		seqNums:       make(map[*session]map[int]struct{}),
+		subscriptions: make(map[*session]chan *quickfix.Message),
+	}
+
+	s.Logger.Debug().Msg("Client connected")
+
+	defer func() {
+		close(c.done)
+		conn.Close()
+
+		c.mux.Lock()
+		for sess, ch := range c.subscriptions {
+			sess.app.Unsubscribe(sess.id, ch)
+		}
+		c.mux.Unlock()
+
+		s.Logger.Debug().Msg("Client disconnected")
+	}()
+
+	go func() {
+		encoder := json.NewEncoder(conn)
+		for {
+			select {
+			case <-c.done:
+				return
+			case response := <-c.responses:
+				if err := encoder.Encode(response); err != nil {
+					s.Logger.Error().Err(err).Msg("Unable to write response")
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	decoder := json.NewDecoder(conn)
+	for {
+		request := Request{}
+		if err := decoder.Decode(&request); err != nil {
+			return
+		}
+
+		seqNum, err := c.send(request)
+		response := Response{Sent: true, SeqNum: seqNum}
+		if err != nil {
+			s.Logger.Error().Err(err).Str("context", request.Context).Str("session", request.Session).Msg("Unable to send message")
+			response = Response{Error: err.Error()}
+		}
+
+		select {
+		case c.responses <- response:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// send sends the message of the request after subscribing the client to the
+// messages of the session so that none of the replies is missed. It returns
+// the sequence number the message was sent with.
+func (c *client) send(request Request) (int, error) {
+	sess, ok := c.server.sessions[sessionKey(request.Context, request.Session)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", errors.DaemonSessionNotFound, sessionKey(request.Context, request.Session))
+	}
+
+	if !sess.app.LoggedOn(sess.id) {
+		return 0, fmt.Errorf("%w: %s", errors.DaemonSessionNotLoggedOn, sess.id)
+	}
+
+	msg := quickfix.NewMessage()
+	err := quickfix.ParseMessageWithDataDictionary(msg, bytes.NewBufferString(request.Message), sess.transportDict, sess.appDict)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mux.Lock()
+	for _, t := range sentCorrelationTags {
+		if id, err := msg.Body.GetString(t); err == nil {
+			c.ids[id] = struct{}{}
+		}
+	}
+	if _, ok := c.subscriptions[sess]; !ok {
+		ch := sess.app.Subscribe(sess.id)
+		c.subscriptions[sess] = ch
+		c.seqNums[sess] = make(map[int]struct{})
+		go c.relay(sess, ch)
+	}
+	c.mux.Unlock()
+
+	if err = quickfix.SendToTarget(msg, sess.id); err != nil {
+		return 0, err
+	}
+
+	// The session sets the sequence number before SendToTarget returns
+	seqNum, err := msg.Header.GetInt(tag.MsgSeqNum)
+	if err == nil {
+		c.mux.Lock()
+		c.seqNums[sess][seqNum] = struct{}{}
+		c.mux.Unlock()
+	}
+
+	return seqNum, nil
+}
+
+// relay writes back the messages of a session referencing the client ids or
+// sequence numbers.
+func (c *client) relay(sess *session, ch chan *quickfix.Message) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-ch:
+			if !c.correlates(sess, msg) {
+				continue
+			}
+
+			select {
+			case c.responses <- Response{Message: msg.String()}:
+			case <-c.done:
+				return
+			}
+		}
+	}
+}
+
+func (c *client) correlates(sess *session, msg *quickfix.Message) bool {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	if msg.IsMsgTypeOf(string(enum.MsgType_REJECT)) {
+		refSeqNum, err := msg.Body.GetInt(tag.RefSeqNum)
+		_, ok := c.seqNums[sess][refSeqNum]
+		return err == nil && ok
+	}
+
+	for _, t := range receivedCorrelationTags {
+		if id, err := msg.Body.GetString(t); err == nil {
+			if _, ok := c.ids[id]; ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
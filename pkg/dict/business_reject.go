@@ -0,0 +1,16 @@
+package dict
+
+import (
+	"github.com/quickfixgo/enum"
+)
+
+var BusinessRejectReasons = map[string]enum.BusinessRejectReason{
+	"OTHER":                                     enum.BusinessRejectReason_OTHER,
+	"UNKNOWN_ID":                                enum.BusinessRejectReason_UNKNOWN_ID,
+	"UNKNOWN_SECURITY":                          enum.BusinessRejectReason_UNKNOWN_SECURITY,
+	"UNSUPPORTED_MESSAGE_TYPE":                  enum.BusinessRejectReason_UNSUPPORTED_MESSAGE_TYPE,
+	"APPLICATION_NOT_AVAILABLE":                 enum.BusinessRejectReason_APPLICATION_NOT_AVAILABLE,
+	"CONDITIONALLY_REQUIRED_FIELD_MISSING":      enum.BusinessRejectReason_CONDITIONALLY_REQUIRED_FIELD_MISSING,
+	"NOT_AUTHORIZED":                            enum.BusinessRejectReason_NOT_AUTHORIZED,
+	"DELIVERTO_FIRM_NOT_AVAILABLE_AT_THIS_TIME": enum.BusinessRejectReason_DELIVERTO_FIRM_NOT_AVAILABLE_AT_THIS_TIME,
+}
@@ -148,3 +148,21 @@ var OrderOriginations = map[string]enum.OrderOrigination{
 	"A_FOREIGN_DEALER_EQUIVALENT":                   enum.OrderOrigination_ORDER_RECEIVED_FROM_A_FOREIGN_DEALER_EQUIVALENT,
 	"AN_EXECUTION_ONLY_SERVICE":                     enum.OrderOrigination_ORDER_RECEIVED_FROM_AN_EXECUTION_ONLY_SERVICE,
 }
+
+var OrdRejReasons = map[string]enum.OrdRejReason{
+	"BROKER_EXCHANGE_OPTION":           enum.OrdRejReason_BROKER_EXCHANGE_OPTION,
+	"UNKNOWN_SYMBOL":                   enum.OrdRejReason_UNKNOWN_SYMBOL,
+	"EXCHANGE_CLOSED":                  enum.OrdRejReason_EXCHANGE_CLOSED,
+	"ORDER_EXCEEDS_LIMIT":              enum.OrdRejReason_ORDER_EXCEEDS_LIMIT,
+	"TOO_LATE_TO_ENTER":                enum.OrdRejReason_TOO_LATE_TO_ENTER,
+	"UNKNOWN_ORDER":                    enum.OrdRejReason_UNKNOWN_ORDER,
+	"DUPLICATE_ORDER":                  enum.OrdRejReason_DUPLICATE_ORDER,
+	"STALE_ORDER":                      enum.OrdRejReason_STALE_ORDER,
+	"INVALID_INVESTOR_ID":              enum.OrdRejReason_INVALID_INVESTOR_ID,
+	"UNSUPPORTED_ORDER_CHARACTERISTIC": enum.OrdRejReason_UNSUPPORTED_ORDER_CHARACTERISTIC,
+	"INCORRECT_QUANTITY":               enum.OrdRejReason_INCORRECT_QUANTITY,
+	"UNKNOWN_ACCOUNT":                  enum.OrdRejReason_UNKNOWN_ACCOUNT,
+	"PRICE_EXCEEDS_CURRENT_PRICE_BAND": enum.OrdRejReason_PRICE_EXCEEDS_CURRENT_PRICE_BAND,
+	"INVALID_PRICE_INCREMENT":          enum.OrdRejReason_INVALID_PRICE_INCREMENT,
+	"OTHER":                            enum.OrdRejReason_OTHER,
+}
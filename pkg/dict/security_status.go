@@ -0,0 +1,32 @@
+package dict
+
+import (
+	"github.com/quickfixgo/enum"
+)
+
+var SecurityTradingStatuses = map[string]enum.SecurityTradingStatus{
+	"OPENING_DELAY":             enum.SecurityTradingStatus_OPENING_DELAY,
+	"TRADING_HALT":              enum.SecurityTradingStatus_TRADING_HALT,
+	"RESUME":                    enum.SecurityTradingStatus_RESUME,
+	"NO_OPEN_NO_RESUME":         enum.SecurityTradingStatus_NO_OPEN_NO_RESUME,
+	"READY_TO_TRADE":            enum.SecurityTradingStatus_READY_TO_TRADE,
+	"NOT_AVAILABLE_FOR_TRADING": enum.SecurityTradingStatus_NOT_AVAILABLE_FOR_TRADING,
+	"NOT_TRADED_ON_THIS_MARKET": enum.SecurityTradingStatus_NOT_TRADED_ON_THIS_MARKET,
+	"UNKNOWN_OR_INVALID":        enum.SecurityTradingStatus_UNKNOWN_OR_INVALID,
+	"PRE_OPEN":                  enum.SecurityTradingStatus_PRE_OPEN,
+	"OPENING_ROTATION":          enum.SecurityTradingStatus_OPENING_ROTATION,
+	"FAST_MARKET":               enum.SecurityTradingStatus_FAST_MARKET,
+	"PRE_CROSS":                 enum.SecurityTradingStatus_PRE_CROSS,
+	"CROSS":                     enum.SecurityTradingStatus_CROSS,
+	"POST_CLOSE":                enum.SecurityTradingStatus_POST_CLOSE,
+}
+
+var TradSesStatuses = map[string]enum.TradSesStatus{
+	"UNKNOWN":          enum.TradSesStatus_UNKNOWN,
+	"HALTED":           enum.TradSesStatus_HALTED,
+	"OPEN":             enum.TradSesStatus_OPEN,
+	"CLOSED":           enum.TradSesStatus_CLOSED,
+	"PRE_OPEN":         enum.TradSesStatus_PRE_OPEN,
+	"PRE_CLOSE":        enum.TradSesStatus_PRE_CLOSE,
+	"REQUEST_REJECTED": enum.TradSesStatus_REQUEST_REJECTED,
+}
@@ -11,40 +11,96 @@ var (
 )
 
 var (
-	Config                          = errors.New("configuration")
-	ConfigAcceptorNotFound          = fmt.Errorf("%w: acceptor not found", Config)
-	ConfigAlreadyExists             = fmt.Errorf("%w: already exists", Config)
-	ConfigCanNotBeCreated           = fmt.Errorf("%w: file can not be created", Config)
-	ConfigContextMultipleSessions   = fmt.Errorf("%w: multiple sessions in initiator context", Config)
-	ConfigContextNoSession          = fmt.Errorf("%w: context has no session", Config)
-	ConfigContextNotFound           = fmt.Errorf("%w: context not found", Config)
-	ConfigDuplicateContextName      = fmt.Errorf("%w: duplicate context name", Config)
-	ConfigDuplicateInitiatorName    = fmt.Errorf("%w: duplicate acceptor name", Config)
-	ConfigDuplicateSessionName      = fmt.Errorf("%w: duplicate session name", Config)
-	ConfigInitiatorNotFound         = fmt.Errorf("%w: initiator not found", Config)
-	ConfigSessionNotFound           = fmt.Errorf("%w: session not found", Config)
-	ConfigSessionNotInContext       = fmt.Errorf("%w: session name not in context", Config)
-	ConnectionTimeout               = errors.New("connection timeout")
-	Fix                             = errors.New("FIX")
-	FixLogout                       = fmt.Errorf("%w: logout received", Fix)
-	FixOrderCanceled                = fmt.Errorf("%w: canceled order", Fix)
-	FixOrderRejected                = fmt.Errorf("%w: rejected order", Fix)
-	FixVersionNotImplemented        = fmt.Errorf("%w: version not implemented", Fix)
-	FixOrderStatusUnknown           = fmt.Errorf("%w: unknown order status", Fix)
-	NotImplemented                  = errors.New("not implemented")
-	Options                         = errors.New("options")
-	OptionsInvalidMarketPrice       = fmt.Errorf("%w: can't give price for market order", Options)
-	OptionsNoSymbolGiven            = fmt.Errorf("%w: no symbol given", Options)
-	OptionsNoTypeGiven              = fmt.Errorf("%w: no type given", Options)
-	OptionsNoPriceGiven             = fmt.Errorf("%w: no price given", Options)
-	OptionsInconsistentValues       = fmt.Errorf("%w: inconsistent values", Options)
-	OptionOrderSideUnknown          = fmt.Errorf("%w: unknown order side", Options)
-	OptionOrderTypeUnknown          = fmt.Errorf("%w: unknown order type", Options)
-	OptionOrderOriginationUnknown   = fmt.Errorf("%w: unknown order origination", Options)
-	OptionOrderAttributeTypeUnkonwn = fmt.Errorf("%w: unknown order attribute type", Options)
-	OptionOrderRoleUnknown          = fmt.Errorf("%w: unknown order role", Options)
-	OptionOrderRoleQualifierUnknown = fmt.Errorf("%w: unknown order role qualifier", Options)
-	OptionOrderIDSourceUnknown      = fmt.Errorf("%w: unknown order id source", Options)
-	OptionPartySubIDTypeUnknown     = fmt.Errorf("%w: unknown party sub id type", Options)
-	ResponseTimeout                 = errors.New("timeout while waiting for response")
+	Acceptor                           = errors.New("acceptor")
+	AcceptorAuctionOrder               = fmt.Errorf("%w: IOC and FOK orders are not accepted during auctions", Acceptor)
+	AcceptorBridgeUnknownSession       = fmt.Errorf("%w: unknown session", Acceptor)
+	AcceptorBridgeUnsupportedMessage   = fmt.Errorf("%w: unsupported message type", Acceptor)
+	AcceptorCompIDNotAllowed           = fmt.Errorf("%w: CompID not allowed", Acceptor)
+	AcceptorInvalidMessage             = fmt.Errorf("%w: invalid FIX message", Acceptor)
+	AcceptorInvalidCredentials         = fmt.Errorf("%w: invalid username or password", Acceptor)
+	AcceptorMarketDataNoSymbol         = fmt.Errorf("%w: market data request has no symbol", Acceptor)
+	AcceptorMarketDataRequestDuplicate = fmt.Errorf("%w: duplicate market data request id", Acceptor)
+	AcceptorMarketDataRequestNotFound  = fmt.Errorf("%w: market data request not found", Acceptor)
+	AcceptorOrderFinal                 = fmt.Errorf("%w: order is in a final state", Acceptor)
+	AcceptorOrderNotAccepted           = fmt.Errorf("%w: orders not accepted in trading phase", Acceptor)
+	AcceptorOrderNotFound              = fmt.Errorf("%w: order not found", Acceptor)
+	AcceptorOrderQuantityTooLow        = fmt.Errorf("%w: order quantity lower than executed quantity", Acceptor)
+	AcceptorSessionLoggedOn            = fmt.Errorf("%w: session is logged on", Acceptor)
+	AcceptorSessionNotFound            = fmt.Errorf("%w: session not found", Acceptor)
+	AcceptorSessionNotLoggedOn         = fmt.Errorf("%w: session is not logged on", Acceptor)
+	AcceptorStopTriggerNotExternal     = fmt.Errorf("%w: stop orders are not triggered by external prices", Acceptor)
+	AcceptorTradeRequestNotSupported   = fmt.Errorf("%w: unsupported trade request type", Acceptor)
+	Config                             = errors.New("configuration")
+	ConfigAcceptorNotFound             = fmt.Errorf("%w: acceptor not found", Config)
+	ConfigAlreadyExists                = fmt.Errorf("%w: already exists", Config)
+	ConfigCanNotBeCreated              = fmt.Errorf("%w: file can not be created", Config)
+	ConfigContextNoSession             = fmt.Errorf("%w: context has no session", Config)
+	ConfigContextNotFound              = fmt.Errorf("%w: context not found", Config)
+	ConfigDuplicateContextName         = fmt.Errorf("%w: duplicate context name", Config)
+	ConfigDuplicateInitiatorName       = fmt.Errorf("%w: duplicate acceptor name", Config)
+	ConfigDuplicateSessionName         = fmt.Errorf("%w: duplicate session name", Config)
+	ConfigInitiatorNotFound            = fmt.Errorf("%w: initiator not found", Config)
+	ConfigInvalidCancelOnDisconnect    = fmt.Errorf("%w: invalid cancel-on-disconnect policy", Config)
+	ConfigInvalidCompIDPattern         = fmt.Errorf("%w: invalid CompID pattern", Config)
+	ConfigInvalidFillMode              = fmt.Errorf("%w: invalid fill mode", Config)
+	ConfigInvalidStopTrigger           = fmt.Errorf("%w: invalid stop trigger", Config)
+	ConfigInvalidThrottle              = fmt.Errorf("%w: invalid throttle", Config)
+	ConfigSessionNotFound              = fmt.Errorf("%w: session not found", Config)
+	ConfigSessionNotInContext          = fmt.Errorf("%w: session name not in context", Config)
+	ConnectionTimeout                  = errors.New("connection timeout")
+	Daemon                             = errors.New("daemon")
+	DaemonAlreadyRunning               = fmt.Errorf("%w: already running", Daemon)
+	DaemonSessionNotFound              = fmt.Errorf("%w: session not found", Daemon)
+	DaemonSessionNotLoggedOn           = fmt.Errorf("%w: session not logged on", Daemon)
+	Fix                                = errors.New("FIX")
+	FixLogout                          = fmt.Errorf("%w: logout received", Fix)
+	FixMessageRejected                 = fmt.Errorf("%w: rejected message", Fix)
+	FixOrderCanceled                   = fmt.Errorf("%w: canceled order", Fix)
+	FixOrderRejected                   = fmt.Errorf("%w: rejected order", Fix)
+	FixVersionNotImplemented           = fmt.Errorf("%w: version not implemented", Fix)
+	FixOrderStatusUnknown              = fmt.Errorf("%w: unknown order status", Fix)
+	NotImplemented                     = errors.New("not implemented")
+	Options                            = errors.New("options")
+	OptionsInvalidMarketPrice          = fmt.Errorf("%w: can't give price for market order", Options)
+	OptionsNoSymbolGiven               = fmt.Errorf("%w: no symbol given", Options)
+	OptionsNoTypeGiven                 = fmt.Errorf("%w: no type given", Options)
+	OptionsNoPriceGiven                = fmt.Errorf("%w: no price given", Options)
+	OptionsInconsistentValues          = fmt.Errorf("%w: inconsistent values", Options)
+	OptionsUnsupportedFileFormat       = fmt.Errorf("%w: unsupported file format", Options)
+	OptionDistributionUnknown          = fmt.Errorf("%w: unknown distribution", Options)
+	OptionOrderSideUnknown             = fmt.Errorf("%w: unknown order side", Options)
+	OptionOrderTypeUnknown             = fmt.Errorf("%w: unknown order type", Options)
+	OptionOrderOriginationUnknown      = fmt.Errorf("%w: unknown order origination", Options)
+	OptionOrderAttributeTypeUnkonwn    = fmt.Errorf("%w: unknown order attribute type", Options)
+	OptionOrderRoleUnknown             = fmt.Errorf("%w: unknown order role", Options)
+	OptionOrderRoleQualifierUnknown    = fmt.Errorf("%w: unknown order role qualifier", Options)
+	OptionOrderIDSourceUnknown         = fmt.Errorf("%w: unknown order id source", Options)
+	OptionPartySubIDTypeUnknown        = fmt.Errorf("%w: unknown party sub id type", Options)
+	RefData                            = errors.New("reference data")
+	RefDataDuplicateSymbol             = fmt.Errorf("%w: duplicate symbol", RefData)
+	RefDataInvalidSchedule             = fmt.Errorf("%w: invalid schedule", RefData)
+	RefDataUnknownPhase                = fmt.Errorf("%w: unknown trading phase", RefData)
+	RefDataUnknownStatus               = fmt.Errorf("%w: unknown status", RefData)
+	RefDataUnknownSymbol               = fmt.Errorf("%w: unknown symbol", RefData)
+	RefDataUnknownTradingSession       = fmt.Errorf("%w: unknown trading session", RefData)
+	Risk                               = errors.New("risk")
+	RiskInvalidLimit                   = fmt.Errorf("%w: invalid limit", Risk)
+	RiskMaxNotional                    = fmt.Errorf("%w: order notional exceeds limit", Risk)
+	RiskMaxOpenOrders                  = fmt.Errorf("%w: too many open orders", Risk)
+	RiskMaxOrderQty                    = fmt.Errorf("%w: order quantity exceeds limit", Risk)
+	RiskPriceCollar                    = fmt.Errorf("%w: price outside of collar", Risk)
+	RiskSymbolNotAllowed               = fmt.Errorf("%w: symbol not allowed", Risk)
+	Rules                              = errors.New("rules")
+	RulesInvalidFillPercent            = fmt.Errorf("%w: fill percent must be between 1 and 100", Rules)
+	RulesInvalidPredicate              = fmt.Errorf("%w: invalid predicate", Rules)
+	RulesPartialFillMarket             = fmt.Errorf("%w: partial fill predicates must exclude market orders", Rules)
+	RulesUnknownAction                 = fmt.Errorf("%w: unknown action", Rules)
+	RulesUnknownField                  = fmt.Errorf("%w: unknown field", Rules)
+	RulesUnknownReason                 = fmt.Errorf("%w: unknown reason", Rules)
+	Sink                               = errors.New("sink")
+	SinkClosed                         = fmt.Errorf("%w: closed", Sink)
+	SinkHTTPQueueFull                  = fmt.Errorf("%w: HTTP queue full", Sink)
+	SinkHTTPStatus                     = fmt.Errorf("%w: unexpected HTTP status", Sink)
+	SinkUnknownType                    = fmt.Errorf("%w: unknown type", Sink)
+	ResponseTimeout                    = errors.New("timeout while waiting for response")
 )
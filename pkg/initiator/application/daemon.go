@@ -0,0 +1,168 @@
+package application
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/utils"
+)
+
+func NewDaemon() *Daemon {
+	d := Daemon{
+		loggedOn:    make(map[quickfix.SessionID]bool),
+		subscribers: make(map[quickfix.SessionID]map[chan *quickfix.Message]struct{}),
+	}
+
+	return &d
+}
+
+// Daemon keeps the sessions of a context open for fix daemon. The application
+// messages and session level rejects received on a session are copied to the
+// channels subscribed to it, a subscriber too slow to read them misses
+// messages rather than blocking the session.
+type Daemon struct {
+	utils.QuickFixAppMessageLogger
+
+	Settings    *quickfix.Settings
+	loggedOn    map[quickfix.SessionID]bool
+	subscribers map[quickfix.SessionID]map[chan *quickfix.Message]struct{}
+	mux         sync.RWMutex
+}
+
+// Subscribe returns a channel receiving the messages of the session until
+// Unsubscribe is called.
+func (app *Daemon) Subscribe(sessionID quickfix.SessionID) chan *quickfix.Message {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	if _, ok := app.subscribers[sessionID]; !ok {
+		app.subscribers[sessionID] = make(map[chan *quickfix.Message]struct{})
+	}
+
+	ch := make(chan *quickfix.Message, 64)
+	app.subscribers[sessionID][ch] = struct{}{}
+
+	return ch
+}
+
+// Unsubscribe stops copying the messages of the session to the channel.
+func (app *Daemon) Unsubscribe(sessionID quickfix.SessionID, ch chan *quickfix.Message) {
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	delete(app.subscribers[sessionID], ch)
+}
+
+// LoggedOn tells whether the session is currently logged on.
+func (app *Daemon) LoggedOn(sessionID quickfix.SessionID) bool {
+	app.mux.RLock()
+	defer app.mux.RUnlock()
+
+	return app.loggedOn[sessionID]
+}
+
+// Notification of a session begin created.
+func (app *Daemon) OnCreate(sessionID quickfix.SessionID) {
+	app.Logger.Debug().Msgf("New session: %s", sessionID)
+}
+
+// Notification of a session successfully logging on.
+func (app *Daemon) OnLogon(sessionID quickfix.SessionID) {
+	app.Logger.Info().Msgf("Logon: %s", sessionID)
+
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	app.loggedOn[sessionID] = true
+}
+
+// Notification of a session logging off or disconnecting.
+func (app *Daemon) OnLogout(sessionID quickfix.SessionID) {
+	app.Logger.Info().Msgf("Logout: %s", sessionID)
+
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	app.loggedOn[sessionID] = false
+}
+
+// Notification of admin message being sent to target.
+func (app *Daemon) ToAdmin(message *quickfix.Message, sessionID quickfix.SessionID) {
+	app.LogMessageType(message, sessionID, "-> Sending message to admin:    ")
+
+	typ, err := message.MsgType()
+	if err != nil {
+		app.Logger.Error().Msgf("Message type error: %s", err)
+	}
+
+	// Logon
+	if err == nil && typ == string(enum.MsgType_LOGON) {
+		sets := app.Settings.SessionSettings()
+		if session, ok := sets[sessionID]; ok {
+			if session.HasSetting("Username") {
+				username, err := session.Setting("Username")
+				if err == nil && len(username) > 0 {
+					app.Logger.Debug().Msg("Username injected in logon message")
+					message.Header.SetField(tag.Username, quickfix.FIXString(username))
+				}
+			}
+			if session.HasSetting("Password") {
+				password, err := session.Setting("Password")
+				if err == nil && len(password) > 0 {
+					app.Logger.Debug().Msg("Password injected in logon message")
+					message.Header.SetField(tag.Password, quickfix.FIXString(password))
+				}
+			}
+		}
+	}
+
+	app.LogMessage(zerolog.TraceLevel, message, sessionID, true)
+}
+
+// Notification of admin message being received from target.
+func (app *Daemon) FromAdmin(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	app.LogMessageType(message, sessionID, "<- Message received from admin: ")
+	app.LogMessage(zerolog.TraceLevel, message, sessionID, false)
+
+	if message.IsMsgTypeOf(string(enum.MsgType_REJECT)) {
+		app.publish(message, sessionID)
+	}
+
+	return nil
+}
+
+// Notification of app message being sent to target.
+func (app *Daemon) ToApp(message *quickfix.Message, sessionID quickfix.SessionID) error {
+	app.LogMessageType(message, sessionID, "-> Sending message to app:      ")
+	app.LogMessage(zerolog.TraceLevel, message, sessionID, true)
+
+	return nil
+}
+
+// Notification of app message being received from target.
+func (app *Daemon) FromApp(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	app.LogMessageType(message, sessionID, "<- Message received from app:   ")
+	app.LogMessage(zerolog.TraceLevel, message, sessionID, false)
+
+	app.publish(message, sessionID)
+
+	return nil
+}
+
+func (app *Daemon) publish(message *quickfix.Message, sessionID quickfix.SessionID) {
+	app.mux.RLock()
+	defer app.mux.RUnlock()
+
+	for ch := range app.subscribers[sessionID] {
+		select {
+		case ch <- message:
+		default:
+			app.Logger.Warn().Msgf("Subscriber too slow, message dropped: %s", sessionID)
+		}
+	}
+}
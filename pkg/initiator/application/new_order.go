@@ -17,11 +17,15 @@ func NewNewOrder() *NewOrder {
 	sod := NewOrder{
 		Connected:       make(chan interface{}),
 		FromAppMessages: make(chan *quickfix.Message, 1),
+		done:            make(chan struct{}),
 	}
 
 	return &sod
 }
 
+// NewOrder can run several sessions at once: Connected receives a value for
+// each session logging on and the messages of all of them are read from
+// FromAppMessages. Both channels are closed as soon as one session logs out.
 type NewOrder struct {
 	utils.QuickFixAppMessageLogger
 
@@ -29,6 +33,9 @@ type NewOrder struct {
 	Connected       chan interface{}
 	FromAppMessages chan *quickfix.Message
 	stopped         bool
+	closed          bool
+	done            chan struct{}
+	stopOnce        sync.Once
 	mux             sync.RWMutex
 }
 
@@ -37,6 +44,9 @@ type NewOrder struct {
 func (app *NewOrder) Stop() {
 	app.Logger.Debug().Msgf("Stopping NewOrder application")
 
+	// Unblock the sessions waiting for the channels to be read
+	app.stopOnce.Do(func() { close(app.done) })
+
 	app.mux.Lock()
 	defer app.mux.Unlock()
 
@@ -57,13 +67,31 @@ func (app *NewOrder) OnCreate(sessionID quickfix.SessionID) {
 func (app *NewOrder) OnLogon(sessionID quickfix.SessionID) {
 	app.Logger.Debug().Msgf("Logon: %s", sessionID)
 
-	app.Connected <- struct{}{}
+	app.mux.RLock()
+	defer app.mux.RUnlock()
+
+	if app.closed {
+		return
+	}
+
+	select {
+	case app.Connected <- struct{}{}:
+	case <-app.done:
+	}
 }
 
 // Notification of a session logging off or disconnecting.
 func (app *NewOrder) OnLogout(sessionID quickfix.SessionID) {
 	app.Logger.Debug().Msgf("Logout: %s", sessionID)
 
+	app.mux.Lock()
+	defer app.mux.Unlock()
+
+	if app.closed {
+		return
+	}
+	app.closed = true
+
 	close(app.Connected)
 	close(app.FromAppMessages)
 }
@@ -106,6 +134,11 @@ func (app *NewOrder) FromAdmin(message *quickfix.Message, sessionID quickfix.Ses
 	app.Logger.Debug().Msgf("<- Message received from admin")
 	app.LogMessage(zerolog.TraceLevel, message, sessionID, false)
 
+	// Session level rejects answer the messages sent by the commands
+	if message.IsMsgTypeOf(string(enum.MsgType_REJECT)) {
+		app.forward(message)
+	}
+
 	return nil
 }
 
@@ -134,20 +167,12 @@ func (app *NewOrder) FromApp(message *quickfix.Message, sessionID quickfix.Sessi
 
 	app.LogMessage(zerolog.TraceLevel, message, sessionID, false)
 
-	app.mux.RLock()
-	if app.stopped {
-		app.mux.RUnlock()
-		return nil
-	}
-	app.mux.RUnlock()
-
 	switch enum.MsgType(typ) {
-	case enum.MsgType_EXECUTION_REPORT:
-		app.FromAppMessages <- message
-	case enum.MsgType_QUOTE_STATUS_REPORT:
-		app.FromAppMessages <- message
-	case enum.MsgType_ORDER_CANCEL_REJECT:
-		app.FromAppMessages <- message
+	case enum.MsgType_EXECUTION_REPORT,
+		enum.MsgType_QUOTE_STATUS_REPORT,
+		enum.MsgType_ORDER_CANCEL_REJECT,
+		enum.MsgType_BUSINESS_MESSAGE_REJECT:
+		app.forward(message)
 	default:
 		typName, err := dict.SearchValue(dict.MessageTypes, enum.MsgType(typ))
 		if err != nil {
@@ -159,3 +184,19 @@ func (app *NewOrder) FromApp(message *quickfix.Message, sessionID quickfix.Sessi
 
 	return nil
 }
+
+// forward gives the message to FromAppMessages unless the application is
+// stopped or a session logged out.
+func (app *NewOrder) forward(message *quickfix.Message) {
+	app.mux.RLock()
+	defer app.mux.RUnlock()
+
+	if app.stopped || app.closed {
+		return
+	}
+
+	select {
+	case app.FromAppMessages <- message:
+	case <-app.done:
+	}
+}
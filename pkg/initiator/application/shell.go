@@ -0,0 +1,133 @@
+package application
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/utils"
+)
+
+func NewShell() *Shell {
+	s := Shell{
+		Connected:       make(chan quickfix.SessionID),
+		Disconnected:    make(chan quickfix.SessionID),
+		FromAppMessages: make(chan *quickfix.Message, 16),
+		done:            make(chan struct{}),
+	}
+
+	return &s
+}
+
+// Shell keeps the sessions of a context open for fix shell. Sessions can log
+// out and back on, the channels are never closed: they stop being written to
+// once the application is stopped.
+type Shell struct {
+	utils.QuickFixAppMessageLogger
+
+	Settings        *quickfix.Settings
+	Connected       chan quickfix.SessionID
+	Disconnected    chan quickfix.SessionID
+	FromAppMessages chan *quickfix.Message
+	done            chan struct{}
+	stopOnce        sync.Once
+}
+
+// Stop unblocks the sessions waiting for the channels to be read so that
+// quickfix can carry on with the LOGOUT process correctly.
+func (app *Shell) Stop() {
+	app.Logger.Debug().Msgf("Stopping Shell application")
+
+	app.stopOnce.Do(func() { close(app.done) })
+}
+
+// Notification of a session begin created.
+func (app *Shell) OnCreate(sessionID quickfix.SessionID) {
+	app.Logger.Debug().Msgf("New session: %s", sessionID)
+}
+
+// Notification of a session successfully logging on.
+func (app *Shell) OnLogon(sessionID quickfix.SessionID) {
+	app.Logger.Debug().Msgf("Logon: %s", sessionID)
+
+	select {
+	case app.Connected <- sessionID:
+	case <-app.done:
+	}
+}
+
+// Notification of a session logging off or disconnecting.
+func (app *Shell) OnLogout(sessionID quickfix.SessionID) {
+	app.Logger.Debug().Msgf("Logout: %s", sessionID)
+
+	select {
+	case app.Disconnected <- sessionID:
+	case <-app.done:
+	}
+}
+
+// Notification of admin message being sent to target.
+func (app *Shell) ToAdmin(message *quickfix.Message, sessionID quickfix.SessionID) {
+	app.LogMessageType(message, sessionID, "-> Sending message to admin:    ")
+
+	typ, err := message.MsgType()
+	if err != nil {
+		app.Logger.Error().Msgf("Message type error: %s", err)
+	}
+
+	// Logon
+	if err == nil && typ == string(enum.MsgType_LOGON) {
+		sets := app.Settings.SessionSettings()
+		if session, ok := sets[sessionID]; ok {
+			if session.HasSetting("Username") {
+				username, err := session.Setting("Username")
+				if err == nil && len(username) > 0 {
+					app.Logger.Debug().Msg("Username injected in logon message")
+					message.Header.SetField(tag.Username, quickfix.FIXString(username))
+				}
+			}
+			if session.HasSetting("Password") {
+				password, err := session.Setting("Password")
+				if err == nil && len(password) > 0 {
+					app.Logger.Debug().Msg("Password injected in logon message")
+					message.Header.SetField(tag.Password, quickfix.FIXString(password))
+				}
+			}
+		}
+	}
+
+	app.LogMessage(zerolog.TraceLevel, message, sessionID, true)
+}
+
+// Notification of admin message being received from target.
+func (app *Shell) FromAdmin(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	app.LogMessageType(message, sessionID, "<- Message received from admin: ")
+	app.LogMessage(zerolog.TraceLevel, message, sessionID, false)
+
+	return nil
+}
+
+// Notification of app message being sent to target.
+func (app *Shell) ToApp(message *quickfix.Message, sessionID quickfix.SessionID) error {
+	app.LogMessageType(message, sessionID, "-> Sending message to app:      ")
+	app.LogMessage(zerolog.TraceLevel, message, sessionID, true)
+
+	return nil
+}
+
+// Notification of app message being received from target.
+func (app *Shell) FromApp(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	app.LogMessageType(message, sessionID, "<- Message received from app:   ")
+	app.LogMessage(zerolog.TraceLevel, message, sessionID, false)
+
+	select {
+	case app.FromAppMessages <- message:
+	case <-app.done:
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"sylr.dev/fix/config"
 	"sylr.dev/fix/pkg/cli/complete"
 	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/utils"
 )
 
 func ValidateOptions(cmd *cobra.Command, args []string) error {
@@ -33,8 +34,8 @@ func ValidateOptions(cmd *cobra.Command, args []string) error {
 	contextName := fixConfig.CurrentContext
 
 	if len(options.Context) > 0 {
-		if len(options.Initiator) > 0 || len(options.Session) > 0 {
-			return fmt.Errorf("%w: can't use --initiator/--session with --context", errors.Options)
+		if len(options.Initiator) > 0 {
+			return fmt.Errorf("%w: can't use --initiator with --context", errors.Options)
 		}
 		contextName = options.Context
 	} else if len(contextName) == 0 {
@@ -66,17 +67,14 @@ func ValidateOptions(cmd *cobra.Command, args []string) error {
 	sessions, err := context.GetSessions()
 	if err != nil {
 		return err
-	} else {
-		switch len(sessions) {
-		case 0:
-			return errors.ConfigContextNoSession
-		case 1:
-			// OK
-		default:
-			if cmd.Name() != "probe" {
-				return errors.ConfigContextMultipleSessions
-			}
-		}
+	} else if len(sessions) == 0 {
+		return errors.ConfigContextNoSession
+	}
+
+	// The session messages are sent on can be picked among the ones of the
+	// context
+	if len(options.Session) > 0 && utils.Search(context.Sessions, options.Session) < 0 {
+		return fmt.Errorf("%w: %s", errors.ConfigSessionNotInContext, options.Session)
 	}
 
 	return nil
@@ -87,7 +85,7 @@ func AddPersistentFlags(cmd *cobra.Command) {
 
 	cmd.PersistentFlags().StringVar(&options.Context, "context", "", "Context to use")
 	cmd.PersistentFlags().StringVar(&options.Initiator, "initiator", "", "Initiator to use (can't be used with --context)")
-	cmd.PersistentFlags().StringVar(&options.Session, "session", "", "Session to use (can't be used with --context)")
+	cmd.PersistentFlags().StringVar(&options.Session, "session", "", "Session to use, or to send messages on among the ones of --context")
 	cmd.PersistentFlags().DurationVar(&options.Timeout, "timeout", 0, "Duration for timeouts")
 	cmd.PersistentFlags().BoolVar(&options.QuickFixLogging, "quickfix-logging", false, "Enable quickfix logging")
 }
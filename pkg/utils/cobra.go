@@ -53,3 +53,43 @@ func ValidateRequiredFlags(c *cobra.Command) error {
 	}
 	return nil
 }
+
+// ResetFlags sets the flags of the command and of its sub-commands back to
+// their default value. Slices are emptied as setting them appends to them once
+// they have been set, RestoreSliceDefaults gives them back their default value
+// after parsing.
+func ResetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			_ = slice.Replace(nil)
+		} else {
+			_ = flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+
+	for _, sub := range cmd.Commands() {
+		ResetFlags(sub)
+	}
+}
+
+// RestoreSliceDefaults gives the slice flags which were not set their default
+// value.
+func RestoreSliceDefaults(flags *pflag.FlagSet) {
+	flags.VisitAll(func(flag *pflag.Flag) {
+		slice, ok := flag.Value.(pflag.SliceValue)
+		if !ok || flag.Changed {
+			return
+		}
+
+		values := strings.TrimSuffix(strings.TrimPrefix(flag.DefValue, "["), "]")
+		if len(values) == 0 {
+			_ = slice.Replace(nil)
+		} else {
+			_ = slice.Replace(strings.Split(values, ","))
+		}
+	})
+}